@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/null93/waterfall/sdk/aws"
+	"github.com/null93/waterfall/sdk/export"
+	"github.com/spf13/cobra"
+)
+
+var (
+	DiffFrom = ""
+	DiffTo   = ""
+)
+
+var DiffCmd = &cobra.Command{
+	Use:   "diff STACK_NAME",
+	Short: "compare the intervals of two operations",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		dataSet, arn := loadDataSet(ctx, args[0])
+		snapshot := dataSet.Snapshot()
+		selection := getSelection(snapshot, arn)
+
+		if DiffTo == "" {
+			DiffTo = selection.Operation
+		}
+		to := getOperation(snapshot, DiffTo)
+		from := aws.Event{}
+		if DiffFrom == "" {
+			previous, ok := snapshot.GetPreviousOperation(to)
+			if !ok {
+				exitWithError(18, "no previous operation to compare with", fmt.Errorf("operation %q is the oldest operation of its stack", to.EventId))
+			}
+			from = previous
+		} else {
+			from = getOperation(snapshot, DiffFrom)
+		}
+
+		if from.StackId != to.StackId {
+			exitWithError(19, "operations belong to different stacks", fmt.Errorf("operation %q is on %s but %q is on %s", from.EventId, from.StackName, to.EventId, to.StackName))
+		}
+
+		if diffErr := export.WriteDiff(os.Stdout, snapshot, from, to); diffErr != nil {
+			exitWithError(14, "failed to write diff", diffErr)
+		}
+
+	},
+}
+
+func getOperation(snapshot *aws.Snapshot, eventId string) aws.Event {
+	operation, ok := snapshot.GetOperation(eventId)
+	if !ok {
+		exitWithError(17, "operation not found", fmt.Errorf("operation %q is not part of the data set", eventId))
+	}
+	return operation
+}
+
+func init() {
+	DiffCmd.Flags().SortFlags = true
+	DiffCmd.Flags().StringVarP(&DiffFrom, "from", "", DiffFrom, "event id of the operation to compare from, defaults to the one before --to")
+	DiffCmd.Flags().StringVarP(&DiffTo, "to", "", DiffTo, "event id of the operation to compare to, defaults to the selected operation")
+	RootCmd.AddCommand(DiffCmd)
+}
@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/null93/waterfall/sdk/aws"
+	"github.com/null93/waterfall/sdk/export"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+)
+
+var (
+	ExportFormat = "json"
+	ExportOutput = ""
+	OtlpEndpoint = ""
+	OtlpHeaders  = map[string]string{}
+	TopResources = 10
+)
+
+var exportFormats = []string{"json", "trace", "otlp", "mermaid", "html", "svg", "markdown", "junit", "csv", "tsv"}
+
+var ExportCmd = &cobra.Command{
+	Use:   "export STACK_NAME",
+	Short: "export operations and intervals to a file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		if formatErr := checkExportFormat(); formatErr != nil {
+			exitWithError(12, "unsupported format", formatErr)
+		}
+
+		dataSet, arn := loadDataSet(ctx, args[0])
+		snapshot := dataSet.Snapshot()
+		selection := getSelection(snapshot, arn)
+
+		if ExportFormat == "otlp" && OtlpEndpoint != "" {
+			sendCtx, sendCancel := withTimeout(ctx)
+			defer sendCancel()
+			if sendErr := export.SendOtlp(sendCtx, OtlpEndpoint, OtlpHeaders, snapshot, selection); sendErr != nil {
+				exitWithError(15, "failed to send traces", sendErr)
+			}
+			return
+		}
+
+		var writer io.Writer = os.Stdout
+
+		if ExportOutput != "" {
+			file, fileErr := os.Create(ExportOutput)
+			if fileErr != nil {
+				exitWithError(13, "failed to create output file", fileErr)
+			}
+			defer file.Close()
+			writer = file
+		}
+
+		if exportErr := writeExport(writer, snapshot, selection); exportErr != nil {
+			exitWithError(14, "failed to write export", exportErr)
+		}
+
+	},
+}
+
+func writeExport(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection) error {
+	format, options, _ := strings.Cut(ExportFormat, "=")
+	switch format {
+	case "json":
+		return export.WriteJson(w, snapshot, selection)
+	case "trace":
+		return export.WriteTrace(w, snapshot, selection)
+	case "otlp":
+		return export.WriteOtlp(w, snapshot, selection)
+	case "mermaid":
+		return export.WriteMermaid(w, snapshot, selection)
+	case "html":
+		return export.WriteHtml(w, snapshot, selection)
+	case "svg":
+		return export.WriteSvg(w, snapshot, selection)
+	case "markdown":
+		return export.WriteMarkdown(w, snapshot, selection, TopResources)
+	case "junit":
+		return export.WriteJunit(w, snapshot, selection)
+	case "csv":
+		return export.WriteCsv(w, snapshot, selection, ',', getCsvColumns(options))
+	case "tsv":
+		return export.WriteCsv(w, snapshot, selection, '\t', getCsvColumns(options))
+	}
+	return fmt.Errorf("format %q is not supported", ExportFormat)
+}
+
+func checkExportFormat() error {
+	format, options, _ := strings.Cut(ExportFormat, "=")
+	if !slices.Contains(exportFormats, format) {
+		return fmt.Errorf("format %q is not supported", ExportFormat)
+	}
+	for _, column := range getCsvColumns(options) {
+		if (format == "csv" || format == "tsv") && !slices.Contains(export.CsvColumns, column) {
+			return fmt.Errorf("unknown column %q", column)
+		}
+	}
+	return nil
+}
+
+func getCsvColumns(options string) []string {
+	columns := []string{}
+	for _, column := range strings.Split(options, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			columns = append(columns, column)
+		}
+	}
+	return columns
+}
+
+func init() {
+	ExportCmd.Long = "Export operations and intervals to a file.\n\nCSV and TSV columns can be chosen with a comma separated list, e.g. --format csv=stack_name,logical_resource_id,duration_seconds\nAvailable columns: " + strings.Join(export.CsvColumns, ", ")
+	ExportCmd.Flags().SortFlags = true
+	ExportCmd.Flags().StringVarP(&ExportFormat, "format", "f", ExportFormat, "export format: json, trace, otlp, mermaid, html, svg, markdown, junit, csv[=COLUMNS], tsv[=COLUMNS]")
+	ExportCmd.Flags().StringVarP(&ExportOutput, "output", "o", ExportOutput, "output file, defaults to stdout")
+	ExportCmd.Flags().StringVarP(&OtlpEndpoint, "otlp-endpoint", "", OtlpEndpoint, "send otlp traces to this otlp/http endpoint instead of writing a file")
+	ExportCmd.Flags().StringToStringVarP(&OtlpHeaders, "otlp-header", "", OtlpHeaders, "extra headers to send to the otlp endpoint")
+	ExportCmd.Flags().IntVarP(&TopResources, "top", "", TopResources, "number of slowest resources to list in markdown reports")
+	RootCmd.AddCommand(ExportCmd)
+}
@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	awsSdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/null93/waterfall/sdk/aws"
+	"golang.org/x/exp/slices"
+)
+
+func loadDataSet(ctx context.Context, stackName string) (*aws.DataSet, string) {
+	lookupCtx, lookupCancel := withTimeout(ctx)
+	defer lookupCancel()
+
+	// initialize aws config and make sure stack exists
+
+	config, configErr := aws.GetConfig(lookupCtx, aws.ConfigOptions{
+		Profile:         AwsProfile,
+		Region:          AwsRegion,
+		RoleArn:         AwsRoleArn,
+		ExternalId:      AwsExternalId,
+		RoleSessionName: AwsSessionName,
+		EndpointUrl:     AwsEndpointUrl,
+	})
+
+	if configErr != nil {
+		exitWithError(1, "failed to load aws config", configErr)
+	}
+
+	authErr := aws.CheckAuth(lookupCtx, config)
+
+	if authErr != nil {
+		exitWithError(2, "failed to authenticate", authErr)
+	}
+
+	var arn string
+	var stackArnErr error
+
+	if DeletedStack {
+		arn, stackArnErr = chooseDeletedStack(ctx, config, stackName)
+	} else {
+		arn, stackArnErr = aws.GetStackArnFromStackName(lookupCtx, config, stackName)
+	}
+
+	if stackArnErr != nil {
+		if stackArnErr == aws.StackNotFoundErr {
+			exitWithError(3, "stack not found", stackArnErr)
+		}
+		exitWithError(4, "unknown error", stackArnErr)
+	}
+
+	// restart timeout so the deleted stack prompt is not counted
+
+	setupCtx, setupCancel := withTimeout(ctx)
+	defer setupCancel()
+
+	rootArn := arn
+
+	if !NoRoot {
+		var rootArnErr error
+		if rootArn, rootArnErr = aws.GetRootStackArn(setupCtx, config, arn); rootArnErr != nil {
+			exitWithError(4, "unknown error", rootArnErr)
+		}
+	}
+
+	// pull stack data from aws
+
+	dataSet := aws.NewDataSet(config, rootArn)
+	dataSet.MaxDepth = MaxDepth
+	dataSet.IgnoreNestedStacks = IgnoreNestedStacks
+	dataSet.Concurrency = Concurrency
+
+	if !IgnoreNestedStacks {
+		if nestedErr := dataSet.AddNestedStacks(setupCtx); nestedErr != nil && (setupCtx.Err() != nil || dataSet.Snapshot().GetStackError(rootArn) != nil) {
+			exitWithError(5, "failed to get nested stacks", nestedErr)
+		}
+	}
+
+	if refreshErr := dataSet.Refresh(setupCtx); refreshErr != nil && dataSet.Snapshot().GetStackError(rootArn) != nil {
+		exitWithError(6, "failed to get stack events", refreshErr)
+	}
+
+	if len(dataSet.Snapshot().GetStackEvents(rootArn)) == 0 {
+		exitWithError(7, "no events found", nil)
+	}
+
+	return dataSet, arn
+}
+
+func getSelection(snapshot *aws.Snapshot, requestedArn string) aws.Selection {
+	selection := aws.Selection{
+		Stack:         snapshot.OriginalStackArn,
+		Operation:     SelectedOperation,
+		AllStacks:     AllStacks,
+		AllOperations: AllOperations,
+	}
+	if slices.Contains(snapshot.GetStackArns(), requestedArn) {
+		selection.Stack = requestedArn
+	}
+	if SelectedStack != "" {
+		selection.Stack = ""
+		for _, stackArn := range snapshot.GetStackArns() {
+			if stackArn == SelectedStack || aws.ExtractStackNameFromArn(stackArn) == SelectedStack {
+				selection.Stack = stackArn
+			}
+		}
+		if selection.Stack == "" {
+			exitWithError(11, "selected stack not found", fmt.Errorf("stack %q is not part of the data set", SelectedStack))
+		}
+	}
+	if selection.Operation == "" {
+		selection.Operation = snapshot.GetLatestOperation(selection.Stack, selection.AllStacks)
+	}
+	return selection
+}
+
+func chooseDeletedStack(ctx context.Context, config awsSdk.Config, stackName string) (string, error) {
+	if aws.IsStackArn(stackName) {
+		return stackName, nil
+	}
+	listCtx, listCancel := withTimeout(ctx)
+	defer listCancel()
+	stacks, err := aws.GetDeletedStacks(listCtx, config, stackName)
+	if err != nil {
+		return "", err
+	}
+	if len(stacks) == 0 {
+		return "", aws.StackNotFoundErr
+	}
+	if DeletedStackIndex < 0 && len(stacks) == 1 {
+		return stacks[0].StackId, nil
+	}
+	if DeletedStackIndex < 0 {
+		fmt.Fprintf(os.Stderr, "%-5s  %-20s  %-20s  %s\n", "INDEX", "CREATED", "DELETED", "STACK ARN")
+		for i, stack := range stacks {
+			fmt.Fprintf(os.Stderr, "%-5d  %-20s  %-20s  %s\n", i, stack.CreationTime.Format(time.RFC3339), stack.DeletionTime.Format(time.RFC3339), stack.StackId)
+		}
+		fmt.Fprint(os.Stderr, "\nSelect index: ")
+		input, readErr := bufio.NewReader(os.Stdin).ReadString('\n')
+		if readErr != nil {
+			return "", readErr
+		}
+		index, parseErr := strconv.Atoi(strings.TrimSpace(input))
+		if parseErr != nil {
+			exitWithError(10, "invalid stack index", parseErr)
+		}
+		DeletedStackIndex = index
+	}
+	if DeletedStackIndex < 0 || DeletedStackIndex >= len(stacks) {
+		exitWithError(10, "invalid stack index", fmt.Errorf("index %d out of range, found %d deleted stacks", DeletedStackIndex, len(stacks)))
+	}
+	return stacks[DeletedStackIndex].StackId, nil
+}
+
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if Timeout > 0 {
+		return context.WithTimeout(ctx, Timeout)
+	}
+	return context.WithCancel(ctx)
+}
@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
@@ -15,9 +16,27 @@ var (
 	Version            = "0.0.0"
 	VerboseOutput      = false
 	AwsProfile         = ""
+	AwsRegion          = ""
+	AwsRoleArn         = ""
+	AwsExternalId      = ""
+	AwsSessionName     = "waterfall"
+	AwsEndpointUrl     = ""
 	RefreshInterval    = 15
 	IgnoreNestedStacks = false
+	MaxDepth           = 0
+	NoRoot             = false
+	Concurrency        = aws.DefaultConcurrency
+	Timeout            = time.Duration(0)
+	DeletedStack       = false
+	DeletedStackIndex  = -1
+	SelectedStack      = ""
+	SelectedOperation  = ""
+	AllStacks          = false
+	AllOperations      = false
 	Debug              = false
+	PrintOutput        = false
+	PrintWidth         = 120
+	NoColor            = os.Getenv("NO_COLOR") != ""
 )
 
 var RootCmd = &cobra.Command{
@@ -26,55 +45,21 @@ var RootCmd = &cobra.Command{
 	Args:    cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 
-		// initialize aws config and make sure stack exists
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
 
-		config, configErr := aws.GetConfig(AwsProfile)
-
-		if configErr != nil {
-			exitWithError(1, "failed to load aws config", configErr)
-		}
-
-		authErr := aws.CheckAuth(config)
-
-		if authErr != nil {
-			exitWithError(2, "failed to authenticate", authErr)
-		}
-
-		arn, stackArnErr := aws.GetStackArnFromStackName(config, args[0])
-
-		if stackArnErr != nil {
-			if stackArnErr == aws.StackNotFoundErr {
-				exitWithError(3, "stack not found", stackArnErr)
-			}
-			exitWithError(4, "unknown error", stackArnErr)
-		}
-
-		// pull stack data from aws
-
-		dataSet := aws.NewDataSet(config, arn)
-
-		if !IgnoreNestedStacks {
-			if nestedErr := dataSet.AddNestedStacks(); nestedErr != nil {
-				exitWithError(5, "failed to get nested stacks", nestedErr)
-			}
-		}
-
-		if refreshErr := dataSet.Refresh(); refreshErr != nil {
-			exitWithError(6, "failed to get stack events", refreshErr)
-		}
-
-		if len(dataSet.GetStackEvents(arn)) == 0 {
-			exitWithError(7, "no events found", nil)
-		}
+		dataSet, arn := loadDataSet(ctx, args[0])
+		selection := getSelection(dataSet.Snapshot(), arn)
 
 		// print debug info and exit if debug mode is enabled
 
 		if Debug {
-			for _, event := range dataSet.GetAllStackEvents() {
+			snapshot := dataSet.Snapshot()
+			for _, event := range snapshot.GetAllStackEvents() {
 				fmt.Printf("%s - %s - %s\n", event.Timestamp.Format(time.RFC3339), event.ResourceStatus, event.LogicalResourceId)
 			}
 			fmt.Println()
-			intervals := dataSet.GetSortedIntervals("", "", true, true)
+			intervals := snapshot.GetSortedIntervals("", "", true, true)
 			for _, interval := range intervals {
 				if interval.Start.IsOperation() {
 					fmt.Println("--- User Initiated ---")
@@ -90,6 +75,15 @@ var RootCmd = &cobra.Command{
 			return
 		}
 
+		// print a static waterfall and exit if print mode is enabled
+
+		if PrintOutput {
+			if err := gui.Print(os.Stdout, dataSet, selection, PrintWidth, !NoColor); err != nil {
+				exitWithError(16, "failed to print waterfall", err)
+			}
+			return
+		}
+
 		// initialize screen
 
 		screen, screenErr := tcell.NewScreen()
@@ -107,9 +101,24 @@ var RootCmd = &cobra.Command{
 
 		output := gui.NewState(screen, dataSet)
 		output.CurrentView = gui.VIEW_WATERFALL
-		output.SelectedOperation = dataSet.GetLatestOperation(output.SelectedStack, output.AllStacks)
+		output.SelectedStack = selection.Stack
+		output.SelectedOperation = selection.Operation
+		output.AllStacks = selection.AllStacks
+		output.AllOperations = selection.AllOperations
 		output.Render()
 
+		// refresh data in the background and ask the main loop to render
+
+		refresh := func() {
+			refreshCtx, refreshCancel := withTimeout(ctx)
+			defer refreshCancel()
+			if !IgnoreNestedStacks {
+				dataSet.AddNestedStacks(refreshCtx)
+			}
+			dataSet.Refresh(refreshCtx)
+			screen.PostEvent(tcell.NewEventInterrupt(nil))
+		}
+
 		// run ticker to update data and render
 
 		if RefreshInterval > 0 {
@@ -119,12 +128,7 @@ var RootCmd = &cobra.Command{
 			go func() {
 				for range ticker.C {
 					if !dataSet.IsLoading() {
-						output.LastRefreshed = time.Now()
-						if !IgnoreNestedStacks {
-							dataSet.AddNestedStacks()
-						}
-						dataSet.Refresh()
-						output.Render()
+						refresh()
 					}
 				}
 			}()
@@ -135,22 +139,25 @@ var RootCmd = &cobra.Command{
 		for {
 			event := screen.PollEvent()
 			switch event := event.(type) {
+			case *tcell.EventInterrupt:
+				output.Render()
 			case *tcell.EventResize:
 				output.Render()
 				screen.Sync()
 			case *tcell.EventKey:
 				if event.Key() == tcell.KeyCtrlC || event.Key() == tcell.KeyEscape {
+					cancel()
 					screen.Fini()
 					os.Exit(0)
 				}
 				if event.Key() == tcell.KeyUp {
-					if output.CurrentView == gui.VIEW_WATERFALL {
+					if output.CurrentView == gui.VIEW_WATERFALL || output.CurrentView == gui.VIEW_HISTORY {
 						output.DecrementSelected()
 						output.Render()
 					}
 				}
 				if event.Key() == tcell.KeyDown {
-					if output.CurrentView == gui.VIEW_WATERFALL {
+					if output.CurrentView == gui.VIEW_WATERFALL || output.CurrentView == gui.VIEW_HISTORY {
 						output.IncrementSelected()
 						output.Render()
 					}
@@ -167,13 +174,13 @@ var RootCmd = &cobra.Command{
 				}
 				if (event.Key() == tcell.KeyTab && !output.AllStacks) || (event.Key() == tcell.KeyDown && output.CurrentView == gui.VIEW_STACKS) {
 					output.IncrementSelectedStack()
-					output.SelectedOperation = dataSet.GetLatestOperation(output.SelectedStack, output.AllStacks)
+					output.SelectedOperation = dataSet.Snapshot().GetLatestOperation(output.SelectedStack, output.AllStacks)
 					output.ResetSelectedIndex()
 					output.Render()
 				}
 				if (event.Key() == tcell.KeyBacktab && !output.AllStacks) || (event.Key() == tcell.KeyUp && output.CurrentView == gui.VIEW_STACKS) {
 					output.DecrementSelectedStack()
-					output.SelectedOperation = dataSet.GetLatestOperation(output.SelectedStack, output.AllStacks)
+					output.SelectedOperation = dataSet.Snapshot().GetLatestOperation(output.SelectedStack, output.AllStacks)
 					output.ResetSelectedIndex()
 					output.Render()
 				}
@@ -189,23 +196,43 @@ var RootCmd = &cobra.Command{
 					output.CurrentView = gui.VIEW_OPERATIONS
 					output.Render()
 				}
-				if event.Rune() == 'r' && !dataSet.IsLoading() {
-					output.LastRefreshed = time.Now()
-					if !IgnoreNestedStacks {
-						dataSet.AddNestedStacks()
+				if event.Rune() == 'c' {
+					output.CurrentView = gui.VIEW_COMPARE
+					output.Render()
+				}
+				if event.Rune() == 'p' && output.CurrentView == gui.VIEW_OPERATIONS {
+					if output.CompareOperation == output.SelectedOperation {
+						output.CompareOperation = ""
+					} else {
+						output.CompareOperation = output.SelectedOperation
 					}
-					dataSet.Refresh()
 					output.Render()
 				}
+				if event.Rune() == 'H' {
+					output.CurrentView = gui.VIEW_HISTORY
+					output.Render()
+				}
+				if event.Rune() == 't' {
+					output.CurrentView = gui.VIEW_STATS
+					output.Render()
+				}
+				if event.Rune() == 'f' {
+					output.JumpToRootCause()
+					output.CurrentView = gui.VIEW_WATERFALL
+					output.Render()
+				}
+				if event.Rune() == 'r' && !dataSet.IsLoading() {
+					go refresh()
+				}
 				if event.Rune() == 'O' {
 					output.AllOperations = !output.AllOperations
-					output.SelectedOperation = dataSet.GetLatestOperation(output.SelectedStack, output.AllStacks)
+					output.SelectedOperation = dataSet.Snapshot().GetLatestOperation(output.SelectedStack, output.AllStacks)
 					output.ResetSelectedIndex()
 					output.Render()
 				}
 				if event.Rune() == 'S' {
 					output.AllStacks = !output.AllStacks
-					output.SelectedOperation = dataSet.GetLatestOperation(output.SelectedStack, output.AllStacks)
+					output.SelectedOperation = dataSet.Snapshot().GetLatestOperation(output.SelectedStack, output.AllStacks)
 					output.ResetSelectedIndex()
 					output.Render()
 				}
@@ -234,10 +261,29 @@ func exitWithError(exitCode int, message string, err error) {
 
 func init() {
 	RootCmd.Flags().SortFlags = true
-	RootCmd.Flags().StringVarP(&AwsProfile, "profile", "p", AwsProfile, "aws profile name")
-	RootCmd.Flags().BoolVarP(&VerboseOutput, "verbose", "v", VerboseOutput, "verbose output")
+	RootCmd.PersistentFlags().SortFlags = true
+	RootCmd.PersistentFlags().StringVarP(&AwsProfile, "profile", "p", AwsProfile, "aws profile name")
+	RootCmd.PersistentFlags().StringVarP(&AwsRegion, "region", "", AwsRegion, "aws region")
+	RootCmd.PersistentFlags().StringVarP(&AwsRoleArn, "role-arn", "", AwsRoleArn, "arn of a role to assume")
+	RootCmd.PersistentFlags().StringVarP(&AwsExternalId, "external-id", "", AwsExternalId, "external id used when assuming a role")
+	RootCmd.PersistentFlags().StringVarP(&AwsSessionName, "role-session-name", "", AwsSessionName, "session name used when assuming a role")
+	RootCmd.PersistentFlags().StringVarP(&AwsEndpointUrl, "endpoint-url", "", AwsEndpointUrl, "custom aws endpoint url, e.g. localstack")
+	RootCmd.PersistentFlags().BoolVarP(&VerboseOutput, "verbose", "v", VerboseOutput, "verbose output")
+	RootCmd.PersistentFlags().BoolVarP(&IgnoreNestedStacks, "no-nested-stacks", "n", IgnoreNestedStacks, "do not process nested stacks")
+	RootCmd.PersistentFlags().IntVarP(&MaxDepth, "max-depth", "m", MaxDepth, "max nested stack depth, 0 for unlimited")
+	RootCmd.PersistentFlags().BoolVarP(&NoRoot, "no-root", "", NoRoot, "do not resolve a nested stack up to its root stack")
+	RootCmd.PersistentFlags().IntVarP(&Concurrency, "concurrency", "c", Concurrency, "number of stacks to fetch events for in parallel")
+	RootCmd.PersistentFlags().DurationVarP(&Timeout, "timeout", "t", Timeout, "timeout for loading and each refresh, 0 to disable")
+	RootCmd.PersistentFlags().BoolVarP(&DeletedStack, "deleted", "", DeletedStack, "look up a deleted stack by name")
+	RootCmd.PersistentFlags().IntVarP(&DeletedStackIndex, "index", "i", DeletedStackIndex, "index of the deleted stack to use, prompts if not set")
+	RootCmd.PersistentFlags().StringVarP(&SelectedStack, "stack", "", SelectedStack, "name or arn of the stack to select, defaults to STACK_NAME")
+	RootCmd.PersistentFlags().StringVarP(&SelectedOperation, "operation", "", SelectedOperation, "event id of the operation to select, defaults to the latest")
+	RootCmd.PersistentFlags().BoolVarP(&AllStacks, "all-stacks", "", AllStacks, "select all stacks")
+	RootCmd.PersistentFlags().BoolVarP(&AllOperations, "all-operations", "", AllOperations, "select all operations")
 	RootCmd.Flags().IntVarP(&RefreshInterval, "refresh", "r", RefreshInterval, "refresh interval in secs, 0 to disable")
+	RootCmd.Flags().BoolVarP(&PrintOutput, "print", "", PrintOutput, "print a static waterfall to stdout and exit")
+	RootCmd.Flags().IntVarP(&PrintWidth, "width", "w", PrintWidth, "width of the printed waterfall")
+	RootCmd.Flags().BoolVarP(&NoColor, "no-color", "", NoColor, "print without ansi colors")
 	RootCmd.Flags().BoolVarP(&Debug, "debug", "d", Debug, "debug mode")
-	RootCmd.Flags().BoolVarP(&IgnoreNestedStacks, "no-nested-stacks", "n", IgnoreNestedStacks, "do not process nested stacks")
 	RootCmd.Flags().MarkHidden("debug")
 }
@@ -6,7 +6,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
@@ -14,37 +14,63 @@ var (
 	StackNotFoundErr = errors.New("stack not found")
 )
 
-func GetConfig(profile string) (aws.Config, error) {
-	cfg, err := config.LoadDefaultConfig(
-		context.TODO(),
-		config.WithSharedConfigProfile(profile),
-	)
+type ConfigOptions struct {
+	Profile         string
+	Region          string
+	RoleArn         string
+	ExternalId      string
+	RoleSessionName string
+	EndpointUrl     string
+}
+
+func GetConfig(ctx context.Context, options ConfigOptions) (aws.Config, error) {
+	loadOptions := []func(*config.LoadOptions) error{
+		config.WithSharedConfigProfile(options.Profile),
+	}
+	if options.Region != "" {
+		loadOptions = append(loadOptions, config.WithRegion(options.Region))
+	}
+	if options.EndpointUrl != "" {
+		resolver := aws.EndpointResolverWithOptionsFunc(func(service, region string, opts ...interface{}) (aws.Endpoint, error) {
+			return aws.Endpoint{URL: options.EndpointUrl, SigningRegion: region, HostnameImmutable: true}, nil
+		})
+		loadOptions = append(loadOptions, config.WithEndpointResolverWithOptions(resolver))
+	}
+	cfg, err := config.LoadDefaultConfig(ctx, loadOptions...)
 	if err != nil {
 		return aws.Config{}, err
 	}
+	if options.RoleArn != "" {
+		provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), options.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+			if options.ExternalId != "" {
+				o.ExternalID = aws.String(options.ExternalId)
+			}
+			if options.RoleSessionName != "" {
+				o.RoleSessionName = options.RoleSessionName
+			}
+		})
+		cfg.Credentials = aws.NewCredentialsCache(provider)
+	}
 	return cfg, nil
 }
 
-func CheckAuth(cfg aws.Config) error {
+func CheckAuth(ctx context.Context, cfg aws.Config) error {
 	stsClient := sts.NewFromConfig(cfg)
-	_, err := stsClient.GetCallerIdentity(context.TODO(), &sts.GetCallerIdentityInput{})
+	_, err := stsClient.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
 	return err
 }
 
-func GetStackArnFromStackName(cfg aws.Config, stackName string) (string, error) {
-	cfnClient := cloudformation.NewFromConfig(cfg)
-	params := cloudformation.ListStacksInput{}
-	paginator := cloudformation.NewListStacksPaginator(cfnClient, &params)
-	for paginator.HasMorePages() {
-		response, err := paginator.NextPage(context.Background())
-		if err != nil {
-			return "", err
-		}
-		for _, stack := range response.StackSummaries {
-			if aws.ToString(stack.StackName) == stackName {
-				return aws.ToString(stack.StackId), nil
-			}
-		}
+func GetStackArnFromStackName(ctx context.Context, cfg aws.Config, stackName string) (string, error) {
+	if IsStackArn(stackName) {
+		return stackName, nil
 	}
-	return "", StackNotFoundErr
+	return NewCloudFormationSource(cfg).GetStackArn(ctx, stackName)
+}
+
+func GetDeletedStacks(ctx context.Context, cfg aws.Config, stackName string) ([]StackSummary, error) {
+	return NewCloudFormationSource(cfg).ListDeletedStacks(ctx, stackName)
+}
+
+func GetRootStackArn(ctx context.Context, cfg aws.Config, stackArn string) (string, error) {
+	return NewCloudFormationSource(cfg).GetRootStackArn(ctx, stackArn)
 }
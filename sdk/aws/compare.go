@@ -0,0 +1,138 @@
+package aws
+
+import (
+	"fmt"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+type IntervalComparison struct {
+	StackName         string
+	LogicalResourceId string
+	ResourceType      string
+	From              *Interval
+	To                *Interval
+	FromOffset        time.Duration
+	ToOffset          time.Duration
+}
+
+func (c IntervalComparison) IsFromOnly() bool {
+	return c.To == nil
+}
+
+func (c IntervalComparison) IsToOnly() bool {
+	return c.From == nil
+}
+
+func (c IntervalComparison) OffsetDelta() time.Duration {
+	if c.From == nil || c.To == nil {
+		return 0
+	}
+	return c.ToOffset - c.FromOffset
+}
+
+func (c IntervalComparison) DurationDelta() time.Duration {
+	if c.From == nil || c.To == nil {
+		return 0
+	}
+	return c.To.Duration() - c.From.Duration()
+}
+
+func (s *Snapshot) GetPreviousOperation(operation Event) (Event, bool) {
+	operations := s.GetOperations(operation.StackId, false)
+	index := slices.IndexFunc(operations, func(candidate Event) bool { return candidate.EventId == operation.EventId })
+	if index < 0 || index+1 >= len(operations) {
+		return Event{}, false
+	}
+	return operations[index+1], true
+}
+
+func (s *Snapshot) GetOperation(eventId string) (Event, bool) {
+	for _, operation := range s.operations {
+		if operation.EventId == eventId {
+			return operation, true
+		}
+	}
+	return Event{}, false
+}
+
+func (s *Snapshot) CompareOperations(from, to Event) []IntervalComparison {
+	comparisons := []IntervalComparison{}
+	indexes := map[string]int{}
+	toIntervals := s.GetOperationTreeIntervals(to)
+	toStart := getWindowStart(toIntervals)
+	toCounts := map[string]int{}
+	for i, interval := range toIntervals {
+		key := s.getComparisonKey(interval, toCounts)
+		indexes[key] = len(comparisons)
+		comparisons = append(comparisons, IntervalComparison{
+			StackName:         interval.Start.StackName,
+			LogicalResourceId: interval.Start.LogicalResourceId,
+			ResourceType:      interval.Start.ResourceType,
+			To:                &toIntervals[i],
+			ToOffset:          interval.Start.Timestamp.Sub(toStart),
+		})
+	}
+	fromIntervals := s.GetOperationTreeIntervals(from)
+	fromStart := getWindowStart(fromIntervals)
+	fromCounts := map[string]int{}
+	for i, interval := range fromIntervals {
+		key := s.getComparisonKey(interval, fromCounts)
+		offset := interval.Start.Timestamp.Sub(fromStart)
+		if index, ok := indexes[key]; ok {
+			comparisons[index].From = &fromIntervals[i]
+			comparisons[index].FromOffset = offset
+			continue
+		}
+		comparisons = append(comparisons, IntervalComparison{
+			StackName:         interval.Start.StackName,
+			LogicalResourceId: interval.Start.LogicalResourceId,
+			ResourceType:      interval.Start.ResourceType,
+			From:              &fromIntervals[i],
+			FromOffset:        offset,
+		})
+	}
+	slices.SortStableFunc(comparisons, func(a, b IntervalComparison) int {
+		return int(a.getOffset() - b.getOffset())
+	})
+	return comparisons
+}
+
+func (c IntervalComparison) getOffset() time.Duration {
+	if c.To != nil {
+		return c.ToOffset
+	}
+	return c.FromOffset
+}
+
+func (s *Snapshot) getComparisonKey(interval Interval, counts map[string]int) string {
+	key := s.getStackLogicalPath(interval.Start.StackId)
+	if interval.Start.PhysicalResourceId != interval.Start.StackId {
+		key += "/" + interval.Start.LogicalResourceId
+	}
+	counts[key]++
+	return fmt.Sprintf("%s/%d", key, counts[key])
+}
+
+func (s *Snapshot) getStackLogicalPath(stackArn string) string {
+	parentArn, ok := s.parents[stackArn]
+	if !ok {
+		return ""
+	}
+	logicalId := ExtractStackNameFromArn(stackArn)
+	for _, event := range s.stackEvents[parentArn] {
+		if event.PhysicalResourceId == stackArn {
+			logicalId = event.LogicalResourceId
+			break
+		}
+	}
+	return s.getStackLogicalPath(parentArn) + "/" + logicalId
+}
+
+func getWindowStart(intervals []Interval) time.Time {
+	if len(intervals) == 0 {
+		return time.Time{}
+	}
+	return GetWindowInterval(&intervals).Start.Timestamp
+}
@@ -0,0 +1,57 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestCompareOperationsMatchesReplacedNestedStack(t *testing.T) {
+	firstChildArn := "arn:aws:cloudformation:us-east-1:123456789012:stack/test-Child-A/2"
+	secondChildArn := "arn:aws:cloudformation:us-east-1:123456789012:stack/test-Child-B/3"
+	stackType := "AWS::CloudFormation::Stack"
+	source := &fakeSource{
+		events: map[string][]Event{
+			testStackArn: {
+				newTestEvent(0, testStackArn, "test", stackType, testStackArn, types.ResourceStatusCreateInProgress, "User Initiated"),
+				newTestEvent(1, testStackArn, "Child", stackType, firstChildArn, types.ResourceStatusCreateInProgress, ""),
+				newTestEvent(9, testStackArn, "Child", stackType, firstChildArn, types.ResourceStatusCreateComplete, ""),
+				newTestEvent(10, testStackArn, "test", stackType, testStackArn, types.ResourceStatusCreateComplete, ""),
+				newTestEvent(100, testStackArn, "test", stackType, testStackArn, types.ResourceStatusUpdateInProgress, "User Initiated"),
+				newTestEvent(101, testStackArn, "Child", stackType, secondChildArn, types.ResourceStatusUpdateInProgress, "Requested update requires the creation of a new physical resource"),
+				newTestEvent(109, testStackArn, "Child", stackType, secondChildArn, types.ResourceStatusUpdateComplete, ""),
+				newTestEvent(110, testStackArn, "test", stackType, testStackArn, types.ResourceStatusUpdateComplete, ""),
+			},
+			firstChildArn: {
+				newTestEvent(2, firstChildArn, "test-Child-A", stackType, firstChildArn, types.ResourceStatusCreateInProgress, "User Initiated"),
+				newTestEvent(3, firstChildArn, "Topic", stackType, "", types.ResourceStatusCreateInProgress, ""),
+				newTestEvent(7, firstChildArn, "Topic", stackType, "topic-a", types.ResourceStatusCreateComplete, ""),
+				newTestEvent(8, firstChildArn, "test-Child-A", stackType, firstChildArn, types.ResourceStatusCreateComplete, ""),
+			},
+			secondChildArn: {
+				newTestEvent(102, secondChildArn, "test-Child-B", stackType, secondChildArn, types.ResourceStatusCreateInProgress, "User Initiated"),
+				newTestEvent(103, secondChildArn, "Topic", stackType, "", types.ResourceStatusCreateInProgress, ""),
+				newTestEvent(105, secondChildArn, "Topic", stackType, "topic-b", types.ResourceStatusCreateComplete, ""),
+				newTestEvent(108, secondChildArn, "test-Child-B", stackType, secondChildArn, types.ResourceStatusCreateComplete, ""),
+			},
+		},
+		visible: map[string]int{testStackArn: 8, firstChildArn: 4, secondChildArn: 4},
+	}
+	dataSet := NewDataSetFromSource(source, testStackArn)
+	if err := dataSet.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	snapshot := dataSet.Snapshot()
+	from, _ := snapshot.GetOperation("event-00")
+	to, _ := snapshot.GetOperation("event-100")
+	comparisons := snapshot.CompareOperations(from, to)
+	if len(comparisons) != 4 {
+		t.Fatalf("expected 4 comparisons, got %d", len(comparisons))
+	}
+	for _, comparison := range comparisons {
+		if comparison.IsFromOnly() || comparison.IsToOnly() {
+			t.Fatalf("expected %s/%s to be matched", comparison.StackName, comparison.LogicalResourceId)
+		}
+	}
+}
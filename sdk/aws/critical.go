@@ -0,0 +1,94 @@
+package aws
+
+import (
+	"golang.org/x/exp/slices"
+)
+
+func (s *Snapshot) GetCriticalPath(operation Event) []Interval {
+	dependencies := s.GetStackDependencies(operation.StackId)
+	candidates := []Interval{}
+	for _, interval := range s.GetOperationIntervals(operation) {
+		if interval.Start.LogicalResourceId != operation.LogicalResourceId {
+			candidates = append(candidates, interval)
+		}
+	}
+	if len(candidates) == 0 {
+		return []Interval{}
+	}
+	current := candidates[0]
+	for _, candidate := range candidates[1:] {
+		if candidate.End.Timestamp.After(current.End.Timestamp) {
+			current = candidate
+		}
+	}
+	path := []Interval{current}
+	visited := map[string]bool{current.Start.EventId: true}
+	for {
+		var predecessor *Interval
+		for i, candidate := range candidates {
+			if visited[candidate.Start.EventId] || candidate.End.Timestamp.After(current.Start.Timestamp) || !isDependent(dependencies, current, candidate) {
+				continue
+			}
+			if predecessor == nil || candidate.End.Timestamp.After(predecessor.End.Timestamp) {
+				predecessor = &candidates[i]
+			}
+		}
+		if predecessor == nil {
+			break
+		}
+		current = *predecessor
+		visited[current.Start.EventId] = true
+		path = append(path, current)
+	}
+	slices.Reverse(path)
+	criticalPath := []Interval{}
+	nestedOperations := s.GetNestedOperations(operation)
+	for _, interval := range path {
+		criticalPath = append(criticalPath, interval)
+		if interval.Start.ResourceType != "AWS::CloudFormation::Stack" {
+			continue
+		}
+		for _, nestedOperation := range nestedOperations {
+			isNestedStack := nestedOperation.StackId == interval.Start.PhysicalResourceId || nestedOperation.StackId == interval.End.PhysicalResourceId
+			isDuringInterval := !nestedOperation.Timestamp.Before(interval.Start.Timestamp) && !nestedOperation.Timestamp.After(interval.End.Timestamp)
+			if isNestedStack && isDuringInterval {
+				criticalPath = append(criticalPath, s.GetCriticalPath(nestedOperation)...)
+			}
+		}
+	}
+	return criticalPath
+}
+
+func (s *Snapshot) GetSelectedCriticalPath(selection Selection) []Interval {
+	criticalPath := []Interval{}
+	for _, operation := range s.GetSelectedRootOperations(selection) {
+		criticalPath = append(criticalPath, s.GetCriticalPath(operation)...)
+	}
+	return criticalPath
+}
+
+func (s *Snapshot) IsCriticalPathFallback(operation Event) bool {
+	for _, treeOperation := range s.GetOperationTree(operation) {
+		if s.GetStackDependencies(treeOperation.StackId) == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Snapshot) IsSelectedCriticalPathFallback(selection Selection) bool {
+	for _, operation := range s.GetSelectedRootOperations(selection) {
+		if s.IsCriticalPathFallback(operation) {
+			return true
+		}
+	}
+	return false
+}
+
+func isDependent(dependencies Dependencies, current, candidate Interval) bool {
+	if dependencies == nil || current.Start.LogicalResourceId == candidate.Start.LogicalResourceId {
+		return true
+	}
+	return dependencies.DependsOn(current.Start.LogicalResourceId, candidate.Start.LogicalResourceId) ||
+		dependencies.DependsOn(candidate.Start.LogicalResourceId, current.Start.LogicalResourceId)
+}
@@ -0,0 +1,76 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestGetCriticalPath(t *testing.T) {
+	childArn := "arn:aws:cloudformation:us-east-1:123456789012:stack/test-Child/2"
+	stackType := "AWS::CloudFormation::Stack"
+	events := map[string][]Event{
+		testStackArn: {
+			newTestEvent(0, testStackArn, "test", stackType, testStackArn, types.ResourceStatusCreateInProgress, "User Initiated"),
+			newTestEvent(1, testStackArn, "First", "AWS::S3::Bucket", "", types.ResourceStatusCreateInProgress, ""),
+			newTestEvent(2, testStackArn, "Second", "AWS::S3::Bucket", "", types.ResourceStatusCreateInProgress, ""),
+			newTestEvent(3, testStackArn, "Late", "AWS::S3::Bucket", "", types.ResourceStatusCreateInProgress, ""),
+			newTestEvent(4, testStackArn, "First", "AWS::S3::Bucket", "first", types.ResourceStatusCreateComplete, ""),
+			newTestEvent(5, testStackArn, "Second", "AWS::S3::Bucket", "second", types.ResourceStatusCreateComplete, ""),
+			newTestEvent(6, testStackArn, "Child", stackType, childArn, types.ResourceStatusCreateInProgress, ""),
+			newTestEvent(7, testStackArn, "Late", "AWS::S3::Bucket", "late", types.ResourceStatusCreateComplete, ""),
+			newTestEvent(24, testStackArn, "Child", stackType, childArn, types.ResourceStatusCreateComplete, ""),
+			newTestEvent(25, testStackArn, "test", stackType, testStackArn, types.ResourceStatusCreateComplete, ""),
+		},
+		childArn: {
+			newTestEvent(17, childArn, "test-Child", stackType, childArn, types.ResourceStatusCreateInProgress, "User Initiated"),
+			newTestEvent(18, childArn, "Queue", "AWS::SQS::Queue", "", types.ResourceStatusCreateInProgress, ""),
+			newTestEvent(19, childArn, "Queue", "AWS::SQS::Queue", "queue", types.ResourceStatusCreateComplete, ""),
+			newTestEvent(20, childArn, "Topic", "AWS::SNS::Topic", "", types.ResourceStatusCreateInProgress, ""),
+			newTestEvent(22, childArn, "Topic", "AWS::SNS::Topic", "topic", types.ResourceStatusCreateComplete, ""),
+			newTestEvent(23, childArn, "test-Child", stackType, childArn, types.ResourceStatusCreateComplete, ""),
+		},
+	}
+	templates := map[string]string{
+		testStackArn: `{"Resources": {"First": {}, "Second": {}, "Late": {}, "Child": {"DependsOn": "First"}}}`,
+		childArn:     `{"Resources": {"Queue": {}, "Topic": {"Properties": {"Queue": {"Ref": "Queue"}}}}}`,
+	}
+	tests := []struct {
+		name        string
+		templateErr error
+		expected    []string
+		fallback    bool
+	}{
+		{name: "dependencies from templates", expected: []string{"First", "Child", "Queue", "Topic"}},
+		{name: "timing fallback without templates", templateErr: errors.New("access denied"), expected: []string{"Second", "Child", "Queue", "Topic"}, fallback: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			source := &fakeSource{
+				events:      events,
+				visible:     map[string]int{testStackArn: len(events[testStackArn]), childArn: len(events[childArn])},
+				templates:   templates,
+				templateErr: test.templateErr,
+			}
+			dataSet := NewDataSetFromSource(source, testStackArn)
+			if err := dataSet.Refresh(context.Background()); err != nil {
+				t.Fatalf("refresh failed: %v", err)
+			}
+			snapshot := dataSet.Snapshot()
+			operation, _ := snapshot.GetOperation("event-00")
+			path := []string{}
+			for _, interval := range snapshot.GetCriticalPath(operation) {
+				path = append(path, interval.Start.LogicalResourceId)
+			}
+			if !reflect.DeepEqual(path, test.expected) {
+				t.Fatalf("expected path %v, got %v", test.expected, path)
+			}
+			if got := snapshot.IsCriticalPathFallback(operation); got != test.fallback {
+				t.Fatalf("expected fallback %t, got %t", test.fallback, got)
+			}
+		})
+	}
+}
@@ -2,154 +2,224 @@ package aws
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"golang.org/x/exp/slices"
 )
 
+const (
+	DefaultConcurrency = 5
+)
+
 type DataSet struct {
-	cfnClient        *cloudformation.Client
-	loading          bool
-	stacks           []string
-	operations       []Event
-	stackEvents      map[string][]Event
-	OriginalStackArn string
-	StackIntervals   IntervalMap
+	source             EventSource
+	mutex              sync.Mutex
+	loading            atomic.Bool
+	snapshot           atomic.Pointer[Snapshot]
+	OriginalStackArn   string
+	MaxDepth           int
+	IgnoreNestedStacks bool
+	Concurrency        int
+}
+
+type fetchResult[T any] struct {
+	value T
+	err   error
 }
 
 func NewDataSet(cfg aws.Config, arn string) *DataSet {
+	return NewDataSetFromSource(NewCloudFormationSource(cfg), arn)
+}
+
+func NewDataSetFromSource(source EventSource, arn string) *DataSet {
 	ds := &DataSet{
-		cfnClient:        cloudformation.NewFromConfig(cfg),
-		loading:          false,
-		stacks:           []string{arn},
-		operations:       []Event{},
-		stackEvents:      map[string][]Event{},
+		source:           source,
 		OriginalStackArn: arn,
-		StackIntervals:   IntervalMap{},
+		Concurrency:      DefaultConcurrency,
 	}
-	ds.AddStackArn(arn)
+	ds.snapshot.Store(newSnapshot(arn))
 	return ds
 }
 
-func (ds *DataSet) GetStackEvents(stackArn string) []Event {
-	return ds.stackEvents[stackArn]
+func (ds *DataSet) Snapshot() *Snapshot {
+	return ds.snapshot.Load()
 }
 
-func (ds *DataSet) GetAllStackEvents() []Event {
-	allEvents := []Event{}
-	for _, events := range ds.stackEvents {
-		allEvents = append(allEvents, events...)
+func (ds *DataSet) AddStackArn(stackArn string) {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	next := ds.Snapshot().clone()
+	next.addStackArn(stackArn)
+	ds.snapshot.Store(next)
+}
+
+func (ds *DataSet) Refresh(ctx context.Context) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	ds.loading.Store(true)
+	defer ds.loading.Store(false)
+	next := ds.Snapshot().clone()
+	newEventCounts, err := ds.refreshEvents(ctx, next)
+	if ctx.Err() != nil {
+		return ctx.Err()
 	}
-	return allEvents
+	ds.refreshDependencies(ctx, next, newEventCounts)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	refreshIntervals(next, newEventCounts)
+	next.LastRefreshed = time.Now()
+	ds.snapshot.Store(next)
+	return err
 }
 
-func (ds *DataSet) GetLatestOperation(selectedStack string, allStacks bool) string {
-	latestEventId := ""
-	latestTimestamp := time.Unix(0, 0)
-	for _, operation := range ds.GetOperations(selectedStack, allStacks) {
-		if operation.Timestamp.After(latestTimestamp) {
-			latestEventId = operation.EventId
-			latestTimestamp = operation.Timestamp
+func (ds *DataSet) refreshEvents(ctx context.Context, next *Snapshot) (map[string]int, error) {
+	newEventCounts := map[string]int{}
+	stackErrs := []error{}
+	pending := slices.Clone(next.stacks)
+	for len(pending) > 0 {
+		cursors := next.cursors
+		results := fetchConcurrently(ds.getConcurrency(), pending, func(stackArn string) ([]Event, error) {
+			return ds.source.ListStackEvents(ctx, stackArn, cursors[stackArn])
+		})
+		discovered := []string{}
+		for i, stackArn := range pending {
+			events, err := results[i].value, results[i].err
+			if err != nil {
+				next.errors[stackArn] = err
+				stackErrs = append(stackErrs, fmt.Errorf("%s: %w", ExtractStackNameFromArn(stackArn), err))
+				continue
+			}
+			delete(next.errors, stackArn)
+			for _, event := range events {
+				if event.IsOperation() {
+					next.operations = append(next.operations, event)
+				}
+			}
+			newEventCounts[stackArn] = len(events)
+			if len(events) == 0 {
+				continue
+			}
+			next.cursors[stackArn] = events[0].EventId
+			next.stackEvents[stackArn] = append(events, next.stackEvents[stackArn]...)
+			if !ds.IgnoreNestedStacks && (ds.MaxDepth <= 0 || next.GetStackDepth(stackArn) < ds.MaxDepth) {
+				for _, nestedArn := range getNestedStackArnsFromEvents(events) {
+					if !slices.Contains(next.stacks, nestedArn) {
+						next.addNestedStackArn(stackArn, nestedArn)
+						discovered = append(discovered, nestedArn)
+					}
+				}
+			}
 		}
+		pending = discovered
 	}
-	return latestEventId
+	slices.SortFunc(next.operations, func(a, b Event) int { return b.Timestamp.Compare(a.Timestamp) })
+	return newEventCounts, errors.Join(stackErrs...)
 }
 
-func (ds *DataSet) GetStackArns() []string {
-	return ds.stacks
+func (ds *DataSet) getConcurrency() int {
+	if ds.Concurrency <= 0 {
+		return 1
+	}
+	return ds.Concurrency
 }
 
-func (ds *DataSet) AddStackArn(stackArn string) {
-	if _, ok := ds.stackEvents[stackArn]; !ok {
-		ds.stackEvents[stackArn] = []Event{}
+func fetchConcurrently[T any](concurrency int, stackArns []string, fetch func(stackArn string) (T, error)) []fetchResult[T] {
+	results := make([]fetchResult[T], len(stackArns))
+	semaphore := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i, stackArn := range stackArns {
+		wg.Add(1)
+		semaphore <- struct{}{}
+		go func(i int, stackArn string) {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			value, err := fetch(stackArn)
+			results[i] = fetchResult[T]{value: value, err: err}
+		}(i, stackArn)
 	}
-	for _, arn := range ds.stacks {
-		if arn == stackArn {
-			return
+	wg.Wait()
+	return results
+}
+
+func (ds *DataSet) refreshDependencies(ctx context.Context, next *Snapshot, newEventCounts map[string]int) {
+	stackArns := []string{}
+	for _, stackArn := range next.stacks {
+		if _, ok := next.dependencies[stackArn]; newEventCounts[stackArn] > 0 || (!ok && len(next.stackEvents[stackArn]) > 0) {
+			stackArns = append(stackArns, stackArn)
+		}
+	}
+	results := fetchConcurrently(ds.getConcurrency(), stackArns, func(stackArn string) (Dependencies, error) {
+		body, err := ds.source.GetTemplate(ctx, stackArn)
+		if err != nil {
+			return nil, err
+		}
+		return ParseTemplateDependencies(body)
+	})
+	for i, stackArn := range stackArns {
+		next.dependencies[stackArn] = results[i].value
+		if results[i].err != nil {
+			next.templateErrors[stackArn] = results[i].err
+		} else {
+			delete(next.templateErrors, stackArn)
 		}
 	}
-	ds.stacks = append(ds.stacks, stackArn)
 }
 
-func (ds *DataSet) GetOperations(stackArn string, allStacks bool) []Event {
-	ops := []Event{}
-	for _, event := range ds.operations {
-		if event.StackId == stackArn || allStacks {
-			ops = append(ops, event)
+func getNestedStackArnsFromEvents(events []Event) []string {
+	stackArns := []string{}
+	for _, event := range events {
+		if event.ResourceType != "AWS::CloudFormation::Stack" || event.PhysicalResourceId == event.StackId {
+			continue
+		}
+		if IsStackArn(event.PhysicalResourceId) {
+			if !slices.Contains(stackArns, event.PhysicalResourceId) {
+				stackArns = append(stackArns, event.PhysicalResourceId)
+			}
 		}
 	}
-	return ops
+	return stackArns
 }
 
-func (ds *DataSet) Refresh() error {
-	ds.loading = true
-	if err := ds.refreshEvents(); err != nil {
-		ds.loading = false
-		return err
-	}
-	ds.loading = false
-	ds.refreshIntervals()
-	return nil
-}
-
-func (ds *DataSet) refreshEvents() error {
-	operations := []Event{}
-	for _, stackArn := range ds.stacks {
-		events := []Event{}
-		params := cloudformation.DescribeStackEventsInput{StackName: aws.String(stackArn)}
-		paginator := cloudformation.NewDescribeStackEventsPaginator(ds.cfnClient, &params)
-		for paginator.HasMorePages() {
-			output, err := paginator.NextPage(context.TODO())
-			if err != nil {
-				return err
-			}
-			for _, event := range output.StackEvents {
-				item := Event{
-					EventId:              aws.ToString(event.EventId),
-					StackId:              aws.ToString(event.StackId),
-					StackName:            aws.ToString(event.StackName),
-					Timestamp:            aws.ToTime(event.Timestamp),
-					LogicalResourceId:    aws.ToString(event.LogicalResourceId),
-					PhysicalResourceId:   aws.ToString(event.PhysicalResourceId),
-					ResourceStatus:       event.ResourceStatus,
-					ResourceStatusReason: aws.ToString(event.ResourceStatusReason),
-					ResourceType:         aws.ToString(event.ResourceType),
-				}
-				if item.ResourceStatusReason == "User Initiated" {
-					operations = append(operations, item)
-				}
-				events = append(events, item)
+func refreshIntervals(next *Snapshot, newEventCounts map[string]int) {
+	for _, stackArn := range next.stacks {
+		events := next.stackEvents[stackArn]
+		newEventCount := newEventCounts[stackArn]
+		if len(events) == 0 || (newEventCount == 0 && !next.hasOpenIntervals(stackArn)) {
+			continue
+		}
+		affected := len(events) - 1
+		for i := newEventCount; i < len(events); i++ {
+			if events[i].IsOperation() {
+				affected = i
+				break
 			}
 		}
-		newEvents := []Event{}
-		for i := 0; i < len(events); i++ {
-			newEvents = append(newEvents, events[i])
+		for operationId, intervals := range computeIntervals(events[:affected+1]) {
+			next.StackIntervals.SetIntervals(stackArn, operationId, intervals)
 		}
-		ds.stackEvents[stackArn] = newEvents
 	}
-	slices.SortFunc(operations, func(a, b Event) int { return b.Timestamp.Compare(a.Timestamp) })
-	ds.operations = operations
-	return nil
 }
 
-func (ds *DataSet) refreshIntervals() {
-	events := ds.GetAllStackEvents()
-	stackIntervals := IntervalMap{}
+func computeIntervals(events []Event) map[string][]Interval {
+	operationIntervals := map[string][]Interval{}
 	temp := Interval{}
 	seen := map[string]bool{}
-	lastOperationStack := ""
 	lastOperationEventId := ""
 	for i := len(events) - 1; i >= 0; i-- {
 		event := events[i]
 		if event.IsOperation() {
-			lastOperationStack = event.StackId
 			lastOperationEventId = event.EventId
+			operationIntervals[lastOperationEventId] = []Interval{}
 		}
-		if lastOperationStack == "" || lastOperationEventId == "" {
-			panic("last operation stack and event id cannot be empty")
+		if lastOperationEventId == "" {
+			panic("last operation event id cannot be empty")
 		}
 		if _, ok := seen[event.EventId]; !ok {
 			isDuplicateCompleteEvent := strings.HasSuffix(string(event.ResourceStatus), "_COMPLETE")
@@ -165,7 +235,7 @@ func (ds *DataSet) refreshIntervals() {
 						} else {
 							seen[target.EventId] = true
 							temp.End = &target
-							stackIntervals.AppendInterval(lastOperationStack, lastOperationEventId, temp)
+							operationIntervals[lastOperationEventId] = append(operationIntervals[lastOperationEventId], temp)
 							temp = Interval{}
 							break
 						}
@@ -173,7 +243,7 @@ func (ds *DataSet) refreshIntervals() {
 				}
 				if temp.Start != nil && temp.End == nil {
 					temp.End = &Event{Timestamp: time.Now(), ResourceStatus: temp.Start.ResourceStatus}
-					stackIntervals.AppendInterval(lastOperationStack, lastOperationEventId, temp)
+					operationIntervals[lastOperationEventId] = append(operationIntervals[lastOperationEventId], temp)
 					temp = Interval{}
 				}
 			} else if temp.Start != nil && temp.Start.LogicalResourceId == event.LogicalResourceId && temp.Start.StackId == event.StackId {
@@ -183,45 +253,60 @@ func (ds *DataSet) refreshIntervals() {
 		}
 		seen[event.EventId] = true
 	}
-	for _, operationIntervals := range stackIntervals {
-		for _, intervals := range operationIntervals {
-			slices.Reverse(intervals)
-		}
+	for _, intervals := range operationIntervals {
+		slices.Reverse(intervals)
 	}
-	ds.StackIntervals = stackIntervals
+	return operationIntervals
 }
 
-func (ds *DataSet) AddNestedStacks() error {
-	ds.loading = true
-	params := cloudformation.DescribeStackResourcesInput{StackName: aws.String(ds.OriginalStackArn)}
-	response, err := ds.cfnClient.DescribeStackResources(context.TODO(), &params)
-	if err != nil {
-		ds.loading = false
-		return err
+func (ds *DataSet) AddNestedStacks(ctx context.Context) error {
+	ds.mutex.Lock()
+	defer ds.mutex.Unlock()
+	ds.loading.Store(true)
+	defer ds.loading.Store(false)
+	next := ds.Snapshot().clone()
+	stackErrs := []error{}
+	pending := []string{}
+	for _, stackArn := range next.stacks {
+		if !next.listedStacks[stackArn] && ds.canExpand(next, stackArn) {
+			pending = append(pending, stackArn)
+		}
 	}
-	for _, resource := range response.StackResources {
-		if aws.ToString(resource.ResourceType) == "AWS::CloudFormation::Stack" {
-			stackArn := aws.ToString(resource.PhysicalResourceId)
-			ds.AddStackArn(stackArn)
+	for len(pending) > 0 {
+		results := fetchConcurrently(ds.getConcurrency(), pending, func(stackArn string) ([]string, error) {
+			return ds.source.ListNestedStacks(ctx, stackArn)
+		})
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		discovered := []string{}
+		for i, parentArn := range pending {
+			if err := results[i].err; err != nil {
+				next.nestedErrors[parentArn] = err
+				stackErrs = append(stackErrs, fmt.Errorf("%s: %w", ExtractStackNameFromArn(parentArn), err))
+				continue
+			}
+			delete(next.nestedErrors, parentArn)
+			next.listedStacks[parentArn] = true
+			for _, stackArn := range results[i].value {
+				if stackArn != "" && !slices.Contains(next.stacks, stackArn) {
+					next.addNestedStackArn(parentArn, stackArn)
+					if ds.canExpand(next, stackArn) {
+						discovered = append(discovered, stackArn)
+					}
+				}
+			}
 		}
+		pending = discovered
 	}
-	ds.loading = false
-	return nil
+	ds.snapshot.Store(next)
+	return errors.Join(stackErrs...)
 }
 
-func (ds *DataSet) IsLoading() bool {
-	return ds.loading
+func (ds *DataSet) canExpand(next *Snapshot, stackArn string) bool {
+	return ds.MaxDepth <= 0 || next.GetStackDepth(stackArn) < ds.MaxDepth
 }
 
-func (ds *DataSet) GetSortedIntervals(selectedStack, selectedOperation string, allStacks, allOperations bool) []Interval {
-	allIntervals := []Interval{}
-	for _, operation := range ds.operations {
-		if allStacks || operation.StackId == selectedStack {
-			if allOperations || operation.EventId == selectedOperation {
-				intervals := ds.StackIntervals.GetIntervals(operation.StackId, operation.EventId)
-				allIntervals = append(allIntervals, intervals...)
-			}
-		}
-	}
-	return allIntervals
+func (ds *DataSet) IsLoading() bool {
+	return ds.loading.Load()
 }
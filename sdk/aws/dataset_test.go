@@ -0,0 +1,230 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+const testStackArn = "arn:aws:cloudformation:us-east-1:123456789012:stack/test/1"
+
+type fakeSource struct {
+	mutex         sync.Mutex
+	events        map[string][]Event
+	visible       map[string]int
+	nested        map[string][]string
+	nestedErrs    map[string]error
+	nestedCalls   map[string]int
+	templates     map[string]string
+	templateErr   error
+	templateCalls int
+}
+
+func (f *fakeSource) ListStackEvents(ctx context.Context, stackArn, cursor string) ([]Event, error) {
+	events := []Event{}
+	visible := f.events[stackArn][:f.visible[stackArn]]
+	for i := len(visible) - 1; i >= 0; i-- {
+		if visible[i].EventId == cursor {
+			break
+		}
+		events = append(events, visible[i])
+	}
+	return events, nil
+}
+
+func (f *fakeSource) ListNestedStacks(ctx context.Context, stackArn string) ([]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.nestedCalls == nil {
+		f.nestedCalls = map[string]int{}
+	}
+	f.nestedCalls[stackArn]++
+	if err := f.nestedErrs[stackArn]; err != nil {
+		return nil, err
+	}
+	return f.nested[stackArn], nil
+}
+
+func (f *fakeSource) GetTemplate(ctx context.Context, stackArn string) (string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.templateCalls++
+	if f.templateErr != nil {
+		return "", f.templateErr
+	}
+	if template, ok := f.templates[stackArn]; ok {
+		return template, nil
+	}
+	return `{"Resources": {}}`, nil
+}
+
+func newTestEvent(second int, stackArn, logicalResourceId, resourceType, physicalResourceId string, status types.ResourceStatus, reason string) Event {
+	return Event{
+		EventId:              fmt.Sprintf("event-%02d", second),
+		StackId:              stackArn,
+		StackName:            ExtractStackNameFromArn(stackArn),
+		Timestamp:            time.Date(2024, 1, 1, 0, 0, second, 0, time.UTC),
+		LogicalResourceId:    logicalResourceId,
+		PhysicalResourceId:   physicalResourceId,
+		ResourceType:         resourceType,
+		ResourceStatus:       status,
+		ResourceStatusReason: reason,
+	}
+}
+
+func newTestEvents() []Event {
+	event := func(second int, logicalResourceId string, status types.ResourceStatus, reason string) Event {
+		return newTestEvent(second, testStackArn, logicalResourceId, "", "", status, reason)
+	}
+	return []Event{
+		event(1, "test", types.ResourceStatusCreateInProgress, "User Initiated"),
+		event(2, "Bucket", types.ResourceStatusCreateInProgress, ""),
+		event(3, "Queue", types.ResourceStatusCreateInProgress, ""),
+		event(4, "Bucket", types.ResourceStatusCreateInProgress, "Resource creation Initiated"),
+		event(5, "Bucket", types.ResourceStatusCreateComplete, ""),
+		event(6, "Queue", types.ResourceStatusCreateComplete, ""),
+		event(7, "test", types.ResourceStatusCreateComplete, ""),
+		event(8, "test", types.ResourceStatusUpdateInProgress, "User Initiated"),
+		event(9, "Queue", types.ResourceStatusUpdateInProgress, ""),
+		event(10, "Queue", types.ResourceStatusUpdateComplete, ""),
+		event(11, "test", types.ResourceStatus("UPDATE_COMPLETE_CLEANUP_IN_PROGRESS"), ""),
+		event(12, "test", types.ResourceStatusUpdateComplete, ""),
+	}
+}
+
+func getIntervalSignatures(snapshot *Snapshot) map[string][]string {
+	signatures := map[string][]string{}
+	for _, operation := range snapshot.GetOperations(testStackArn, false) {
+		signatures[operation.EventId] = []string{}
+		for _, interval := range snapshot.GetOperationIntervals(operation) {
+			signature := interval.Start.EventId
+			for _, event := range interval.Intermediate {
+				signature += "," + event.EventId
+			}
+			if interval.IsOpen() {
+				signature += "->open"
+			} else {
+				signature += "->" + interval.End.EventId
+			}
+			signatures[operation.EventId] = append(signatures[operation.EventId], signature)
+		}
+	}
+	return signatures
+}
+
+func TestIncrementalRefreshMatchesFullRecompute(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []int
+	}{
+		{name: "events arriving mid-operation", steps: []int{3, 5, 7}},
+		{name: "open intervals closing", steps: []int{2, 4, 6, 7}},
+		{name: "new operation after cursor", steps: []int{7, 9, 12}},
+		{name: "refresh without new events", steps: []int{4, 4, 12, 12}},
+		{name: "every event individually", steps: []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events := newTestEvents()
+			source := &fakeSource{events: map[string][]Event{testStackArn: events}, visible: map[string]int{}}
+			incremental := NewDataSetFromSource(source, testStackArn)
+			for _, step := range test.steps {
+				source.visible[testStackArn] = step
+				if err := incremental.Refresh(context.Background()); err != nil {
+					t.Fatalf("incremental refresh at %d events failed: %v", step, err)
+				}
+				full := NewDataSetFromSource(source, testStackArn)
+				if err := full.Refresh(context.Background()); err != nil {
+					t.Fatalf("full refresh at %d events failed: %v", step, err)
+				}
+				got := getIntervalSignatures(incremental.Snapshot())
+				want := getIntervalSignatures(full.Snapshot())
+				if !reflect.DeepEqual(got, want) {
+					t.Fatalf("intervals differ at %d events\nincremental: %v\nfull:        %v", step, got, want)
+				}
+				if got, want := len(incremental.Snapshot().GetStackEvents(testStackArn)), step; got != want {
+					t.Fatalf("expected %d events, got %d", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestAddNestedStacksExpandsOnlyNewStacks(t *testing.T) {
+	childArn := "arn:aws:cloudformation:us-east-1:123456789012:stack/test-child/2"
+	brokenArn := "arn:aws:cloudformation:us-east-1:123456789012:stack/test-broken/3"
+	grandchildArn := "arn:aws:cloudformation:us-east-1:123456789012:stack/test-grandchild/4"
+	source := &fakeSource{
+		nested: map[string][]string{
+			testStackArn: {childArn, brokenArn},
+			childArn:     {grandchildArn},
+		},
+		nestedErrs: map[string]error{brokenArn: errors.New("access denied")},
+	}
+	dataSet := NewDataSetFromSource(source, testStackArn)
+	if err := dataSet.AddNestedStacks(context.Background()); err == nil {
+		t.Fatalf("expected error for broken stack")
+	}
+	snapshot := dataSet.Snapshot()
+	if got, want := snapshot.GetStackArns(), []string{testStackArn, childArn, grandchildArn, brokenArn}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected stacks %v, got %v", want, got)
+	}
+	if snapshot.GetStackError(brokenArn) == nil {
+		t.Fatalf("expected error recorded for broken stack")
+	}
+	if snapshot.GetStackError(testStackArn) != nil {
+		t.Fatalf("expected no error for root stack")
+	}
+	delete(source.nestedErrs, brokenArn)
+	if err := dataSet.AddNestedStacks(context.Background()); err != nil {
+		t.Fatalf("second discovery failed: %v", err)
+	}
+	if dataSet.Snapshot().GetStackError(brokenArn) != nil {
+		t.Fatalf("expected broken stack error to clear")
+	}
+	for stackArn, want := range map[string]int{testStackArn: 1, childArn: 1, grandchildArn: 1, brokenArn: 2} {
+		if got := source.nestedCalls[stackArn]; got != want {
+			t.Fatalf("expected %d listings of %s, got %d", want, stackArn, got)
+		}
+	}
+}
+
+func TestTemplateErrorIsCached(t *testing.T) {
+	source := &fakeSource{
+		events:      map[string][]Event{testStackArn: newTestEvents()},
+		visible:     map[string]int{testStackArn: 7},
+		templateErr: errors.New("access denied"),
+	}
+	dataSet := NewDataSetFromSource(source, testStackArn)
+	for i := 0; i < 3; i++ {
+		if err := dataSet.Refresh(context.Background()); err != nil {
+			t.Fatalf("refresh failed: %v", err)
+		}
+	}
+	snapshot := dataSet.Snapshot()
+	if source.templateCalls != 1 {
+		t.Fatalf("expected 1 template fetch, got %d", source.templateCalls)
+	}
+	if snapshot.GetStackTemplateError(testStackArn) == nil {
+		t.Fatalf("expected template error to be recorded")
+	}
+	operation, _ := snapshot.GetOperation("event-01")
+	if !snapshot.IsCriticalPathFallback(operation) {
+		t.Fatalf("expected critical path to be a fallback")
+	}
+	source.templateErr = nil
+	source.visible[testStackArn] = 9
+	if err := dataSet.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	snapshot = dataSet.Snapshot()
+	if source.templateCalls != 2 || snapshot.GetStackTemplateError(testStackArn) != nil || snapshot.IsCriticalPathFallback(operation) {
+		t.Fatalf("expected template to be fetched again after new events")
+	}
+}
@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+const (
+	HistoryOutlierFactor     = 2.0
+	historyMinOutlierEntries = 3
+)
+
+type ResourceHistory struct {
+	StackArn          string
+	LogicalResourceId string
+	Entries           []ResourceHistoryEntry
+	MedianDuration    time.Duration
+}
+
+type ResourceHistoryEntry struct {
+	Operation Event
+	Interval  Interval
+	Duration  time.Duration
+	IsOutlier bool
+}
+
+func (s *Snapshot) GetResourceHistory(stackArn, logicalResourceId string) ResourceHistory {
+	history := ResourceHistory{
+		StackArn:          stackArn,
+		LogicalResourceId: logicalResourceId,
+		Entries:           []ResourceHistoryEntry{},
+	}
+	operations := s.GetOperations(stackArn, false)
+	slices.Reverse(operations)
+	durations := []time.Duration{}
+	for _, operation := range operations {
+		for _, interval := range s.GetOperationIntervals(operation) {
+			if interval.Start.LogicalResourceId != logicalResourceId {
+				continue
+			}
+			history.Entries = append(history.Entries, ResourceHistoryEntry{
+				Operation: operation,
+				Interval:  interval,
+				Duration:  interval.Duration(),
+			})
+			durations = append(durations, interval.Duration())
+		}
+	}
+	slices.Sort(durations)
+	history.MedianDuration = getMedian(durations)
+	if len(history.Entries) >= historyMinOutlierEntries && history.MedianDuration > 0 {
+		for i, entry := range history.Entries {
+			ratio := float64(entry.Duration) / float64(history.MedianDuration)
+			history.Entries[i].IsOutlier = ratio >= HistoryOutlierFactor || ratio <= 1/HistoryOutlierFactor
+		}
+	}
+	return history
+}
+
+func (history ResourceHistory) GetDurations() []time.Duration {
+	durations := []time.Duration{}
+	for _, entry := range history.Entries {
+		durations = append(durations, entry.Duration)
+	}
+	return durations
+}
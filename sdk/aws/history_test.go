@@ -0,0 +1,51 @@
+package aws
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestGetResourceHistoryOutliers(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []int
+		outliers  []bool
+	}{
+		{name: "too few entries", durations: []int{10, 50}, outliers: []bool{false, false}},
+		{name: "at twice the median", durations: []int{10, 10, 20}, outliers: []bool{false, false, true}},
+		{name: "at half the median", durations: []int{10, 10, 5}, outliers: []bool{false, false, true}},
+		{name: "within bounds", durations: []int{10, 10, 19, 6}, outliers: []bool{false, false, false, false}},
+		{name: "both directions", durations: []int{4, 10, 10, 30}, outliers: []bool{true, false, false, true}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			events := []Event{}
+			for i, duration := range test.durations {
+				start := i * 100
+				events = append(
+					events,
+					newTestEvent(start, testStackArn, "test", "", "", types.ResourceStatusUpdateInProgress, "User Initiated"),
+					newTestEvent(start+1, testStackArn, "Bucket", "", "", types.ResourceStatusUpdateInProgress, ""),
+					newTestEvent(start+1+duration, testStackArn, "Bucket", "", "", types.ResourceStatusUpdateComplete, ""),
+					newTestEvent(start+2+duration, testStackArn, "test", "", "", types.ResourceStatusUpdateComplete, ""),
+				)
+			}
+			source := &fakeSource{events: map[string][]Event{testStackArn: events}, visible: map[string]int{testStackArn: len(events)}}
+			dataSet := NewDataSetFromSource(source, testStackArn)
+			if err := dataSet.Refresh(context.Background()); err != nil {
+				t.Fatalf("refresh failed: %v", err)
+			}
+			history := dataSet.Snapshot().GetResourceHistory(testStackArn, "Bucket")
+			outliers := []bool{}
+			for _, entry := range history.Entries {
+				outliers = append(outliers, entry.IsOutlier)
+			}
+			if !reflect.DeepEqual(outliers, test.outliers) {
+				t.Fatalf("expected outliers %v, got %v (median %s, durations %v)", test.outliers, outliers, history.MedianDuration, history.GetDurations())
+			}
+		})
+	}
+}
@@ -2,6 +2,16 @@ package aws
 
 import (
 	"fmt"
+	"strings"
+	"time"
+)
+
+type Outcome string
+
+const (
+	OUTCOME_IN_PROGRESS Outcome = "IN_PROGRESS"
+	OUTCOME_COMPLETE    Outcome = "COMPLETE"
+	OUTCOME_FAILED      Outcome = "FAILED"
 )
 
 type Interval struct {
@@ -10,6 +20,40 @@ type Interval struct {
 	End          *Event
 }
 
+func (i *Interval) IsOpen() bool {
+	return i.End == nil || i.End.EventId == ""
+}
+
+func (i *Interval) Duration() time.Duration {
+	if i.IsOpen() {
+		return time.Since(i.Start.Timestamp)
+	}
+	return i.End.Timestamp.Sub(i.Start.Timestamp)
+}
+
+func (i *Interval) Outcome() Outcome {
+	if i.IsOpen() {
+		return OUTCOME_IN_PROGRESS
+	}
+	if i.Start.IsOperation() {
+		return GetStackStatusOutcome(string(i.End.ResourceStatus))
+	}
+	if strings.HasSuffix(string(i.End.ResourceStatus), "_FAILED") {
+		return OUTCOME_FAILED
+	}
+	return OUTCOME_COMPLETE
+}
+
+func GetStackStatusOutcome(status string) Outcome {
+	if strings.HasSuffix(status, "_IN_PROGRESS") {
+		return OUTCOME_IN_PROGRESS
+	}
+	if strings.Contains(status, "ROLLBACK") || strings.HasSuffix(status, "_FAILED") {
+		return OUTCOME_FAILED
+	}
+	return OUTCOME_COMPLETE
+}
+
 type IntervalMap map[string]map[string][]Interval
 
 func (im IntervalMap) AppendInterval(stackArn string, operationId string, interval Interval) {
@@ -22,6 +66,24 @@ func (im IntervalMap) AppendInterval(stackArn string, operationId string, interv
 	im[stackArn][operationId] = append(im[stackArn][operationId], interval)
 }
 
+func (im IntervalMap) SetIntervals(stackArn string, operationId string, intervals []Interval) {
+	if _, ok := im[stackArn]; !ok {
+		im[stackArn] = map[string][]Interval{}
+	}
+	im[stackArn][operationId] = intervals
+}
+
+func (im IntervalMap) clone() IntervalMap {
+	cloned := IntervalMap{}
+	for stackArn, operationIntervals := range im {
+		cloned[stackArn] = map[string][]Interval{}
+		for operationId, intervals := range operationIntervals {
+			cloned[stackArn][operationId] = intervals
+		}
+	}
+	return cloned
+}
+
 func (im IntervalMap) GetIntervals(selectedStack, selectedOperation string) []Interval {
 	allIntervals := []Interval{}
 	for stackArn, operationIntervals := range im {
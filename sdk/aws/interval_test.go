@@ -0,0 +1,26 @@
+package aws
+
+import "testing"
+
+func TestGetStackStatusOutcome(t *testing.T) {
+	tests := map[string]Outcome{
+		"CREATE_COMPLETE":                              OUTCOME_COMPLETE,
+		"UPDATE_COMPLETE":                              OUTCOME_COMPLETE,
+		"DELETE_COMPLETE":                              OUTCOME_COMPLETE,
+		"IMPORT_COMPLETE":                              OUTCOME_COMPLETE,
+		"CREATE_FAILED":                                OUTCOME_FAILED,
+		"DELETE_FAILED":                                OUTCOME_FAILED,
+		"ROLLBACK_COMPLETE":                            OUTCOME_FAILED,
+		"ROLLBACK_FAILED":                              OUTCOME_FAILED,
+		"UPDATE_ROLLBACK_COMPLETE":                     OUTCOME_FAILED,
+		"UPDATE_ROLLBACK_FAILED":                       OUTCOME_FAILED,
+		"IMPORT_ROLLBACK_COMPLETE":                     OUTCOME_FAILED,
+		"UPDATE_IN_PROGRESS":                           OUTCOME_IN_PROGRESS,
+		"UPDATE_ROLLBACK_COMPLETE_CLEANUP_IN_PROGRESS": OUTCOME_IN_PROGRESS,
+	}
+	for status, want := range tests {
+		if got := GetStackStatusOutcome(status); got != want {
+			t.Errorf("expected %s for %s, got %s", want, status, got)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"strings"
+)
+
+const (
+	rootCauseCandidate = iota
+	rootCauseNestedStack
+	rootCauseCancelled
+)
+
+func (s *Snapshot) GetRootCause(operation Event) *Interval {
+	var rootCause *Interval
+	rootCauseRank := 0
+	for _, treeOperation := range s.GetOperationTree(operation) {
+		for _, interval := range s.GetOperationIntervals(treeOperation) {
+			if interval.Start.IsOperation() || interval.Outcome() != OUTCOME_FAILED {
+				continue
+			}
+			rank := getRootCauseRank(interval)
+			if rootCause == nil || rank < rootCauseRank || (rank == rootCauseRank && interval.End.Timestamp.Before(rootCause.End.Timestamp)) {
+				candidate := interval
+				rootCause = &candidate
+				rootCauseRank = rank
+			}
+		}
+	}
+	return rootCause
+}
+
+func (s *Snapshot) GetSelectedRootCause(selection Selection) *Interval {
+	for _, operation := range s.GetSelectedRootOperations(selection) {
+		if rootCause := s.GetRootCause(operation); rootCause != nil {
+			return rootCause
+		}
+	}
+	return nil
+}
+
+func getRootCauseRank(interval Interval) int {
+	reason := strings.ToLower(interval.End.ResourceStatusReason)
+	if strings.Contains(reason, "cancelled") || strings.Contains(reason, "canceled") {
+		return rootCauseCancelled
+	}
+	if interval.Start.ResourceType == "AWS::CloudFormation::Stack" {
+		return rootCauseNestedStack
+	}
+	return rootCauseCandidate
+}
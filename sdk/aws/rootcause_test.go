@@ -0,0 +1,67 @@
+package aws
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestGetRootCausePrefersResourceFailure(t *testing.T) {
+	childArn := "arn:aws:cloudformation:us-east-1:123456789012:stack/test-Child/2"
+	stackType := "AWS::CloudFormation::Stack"
+	source := &fakeSource{
+		events: map[string][]Event{
+			testStackArn: {
+				newTestEvent(0, testStackArn, "test", stackType, testStackArn, types.ResourceStatusCreateInProgress, "User Initiated"),
+				newTestEvent(1, testStackArn, "Child", stackType, childArn, types.ResourceStatusCreateInProgress, ""),
+				newTestEvent(2, testStackArn, "Bucket", "AWS::S3::Bucket", "", types.ResourceStatusCreateInProgress, ""),
+				newTestEvent(6, testStackArn, "Bucket", "AWS::S3::Bucket", "", types.ResourceStatusCreateFailed, "Resource creation cancelled"),
+				newTestEvent(7, testStackArn, "Child", stackType, childArn, types.ResourceStatusCreateFailed, "Embedded stack was not successfully created"),
+				newTestEvent(13, testStackArn, "test", stackType, testStackArn, types.ResourceStatusRollbackInProgress, "The following resource(s) failed to create: [Child, Bucket]."),
+				newTestEvent(20, testStackArn, "test", stackType, testStackArn, types.ResourceStatusRollbackComplete, ""),
+			},
+			childArn: {
+				newTestEvent(3, childArn, "test-Child", stackType, childArn, types.ResourceStatusCreateInProgress, "User Initiated"),
+				newTestEvent(4, childArn, "Topic", "AWS::SNS::Topic", "", types.ResourceStatusCreateInProgress, ""),
+				newTestEvent(5, childArn, "Queue", "AWS::SQS::Queue", "", types.ResourceStatusCreateInProgress, ""),
+				newTestEvent(8, childArn, "Topic", "AWS::SNS::Topic", "", types.ResourceStatusCreateFailed, "Invalid parameter: DisplayName"),
+				newTestEvent(10, childArn, "Queue", "AWS::SQS::Queue", "", types.ResourceStatusCreateFailed, "Queue name already exists"),
+				newTestEvent(11, childArn, "test-Child", stackType, childArn, types.ResourceStatusRollbackInProgress, "The following resource(s) failed to create: [Topic, Queue]."),
+				newTestEvent(12, childArn, "test-Child", stackType, childArn, types.ResourceStatusRollbackComplete, ""),
+			},
+		},
+		visible: map[string]int{testStackArn: 7, childArn: 7},
+	}
+	dataSet := NewDataSetFromSource(source, testStackArn)
+	if err := dataSet.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	snapshot := dataSet.Snapshot()
+	operation, _ := snapshot.GetOperation("event-00")
+	rootCause := snapshot.GetRootCause(operation)
+	if rootCause == nil {
+		t.Fatalf("expected a root cause")
+	}
+	if rootCause.Start.StackId != childArn || rootCause.Start.LogicalResourceId != "Topic" {
+		t.Fatalf("expected Topic in the child stack, got %s in %s", rootCause.Start.LogicalResourceId, rootCause.Start.StackName)
+	}
+	rankTests := map[string]int{
+		"Topic":  rootCauseCandidate,
+		"Child":  rootCauseNestedStack,
+		"Bucket": rootCauseCancelled,
+	}
+	for _, treeOperation := range snapshot.GetOperationTree(operation) {
+		for _, interval := range snapshot.GetOperationIntervals(treeOperation) {
+			if want, ok := rankTests[interval.Start.LogicalResourceId]; ok {
+				if got := getRootCauseRank(interval); got != want {
+					t.Errorf("expected rank %d for %s, got %d", want, interval.Start.LogicalResourceId, got)
+				}
+				delete(rankTests, interval.Start.LogicalResourceId)
+			}
+		}
+	}
+	if len(rankTests) > 0 {
+		t.Fatalf("intervals not found: %v", rankTests)
+	}
+}
@@ -0,0 +1,39 @@
+package aws
+
+type Selection struct {
+	Stack         string
+	Operation     string
+	AllStacks     bool
+	AllOperations bool
+}
+
+func (s *Snapshot) GetSelectedOperations(selection Selection) []Event {
+	operations := []Event{}
+	for _, operation := range s.GetOperations(selection.Stack, selection.AllStacks) {
+		if selection.AllOperations || operation.EventId == selection.Operation {
+			operations = append(operations, operation)
+		}
+	}
+	return operations
+}
+
+func (s *Snapshot) GetSelectedIntervals(selection Selection) []Interval {
+	return s.GetSortedIntervals(selection.Stack, selection.Operation, selection.AllStacks, selection.AllOperations)
+}
+
+func (s *Snapshot) GetSelectedRootOperations(selection Selection) []Event {
+	operations := s.GetSelectedOperations(selection)
+	nested := map[string]bool{}
+	for _, operation := range operations {
+		for _, nestedOperation := range s.GetOperationTree(operation)[1:] {
+			nested[nestedOperation.EventId] = true
+		}
+	}
+	rootOperations := []Event{}
+	for _, operation := range operations {
+		if !nested[operation.EventId] {
+			rootOperations = append(rootOperations, operation)
+		}
+	}
+	return rootOperations
+}
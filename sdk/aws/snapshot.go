@@ -0,0 +1,242 @@
+package aws
+
+import (
+	"errors"
+	"time"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+type Snapshot struct {
+	OriginalStackArn string
+	stacks           []string
+	parents          map[string]string
+	operations       []Event
+	stackEvents      map[string][]Event
+	cursors          map[string]string
+	errors           map[string]error
+	nestedErrors     map[string]error
+	listedStacks     map[string]bool
+	templateErrors   map[string]error
+	dependencies     map[string]Dependencies
+	StackIntervals   IntervalMap
+	LastRefreshed    time.Time
+}
+
+func newSnapshot(arn string) *Snapshot {
+	return &Snapshot{
+		OriginalStackArn: arn,
+		stacks:           []string{arn},
+		parents:          map[string]string{},
+		operations:       []Event{},
+		stackEvents:      map[string][]Event{arn: {}},
+		cursors:          map[string]string{},
+		errors:           map[string]error{},
+		nestedErrors:     map[string]error{},
+		listedStacks:     map[string]bool{},
+		templateErrors:   map[string]error{},
+		dependencies:     map[string]Dependencies{},
+		StackIntervals:   IntervalMap{},
+		LastRefreshed:    time.Now(),
+	}
+}
+
+func (s *Snapshot) clone() *Snapshot {
+	return &Snapshot{
+		OriginalStackArn: s.OriginalStackArn,
+		stacks:           slices.Clone(s.stacks),
+		parents:          maps.Clone(s.parents),
+		operations:       slices.Clone(s.operations),
+		stackEvents:      maps.Clone(s.stackEvents),
+		cursors:          maps.Clone(s.cursors),
+		errors:           maps.Clone(s.errors),
+		nestedErrors:     maps.Clone(s.nestedErrors),
+		listedStacks:     maps.Clone(s.listedStacks),
+		templateErrors:   maps.Clone(s.templateErrors),
+		dependencies:     maps.Clone(s.dependencies),
+		StackIntervals:   s.StackIntervals.clone(),
+		LastRefreshed:    s.LastRefreshed,
+	}
+}
+
+func (s *Snapshot) addStackArn(stackArn string) {
+	if _, ok := s.stackEvents[stackArn]; !ok {
+		s.stackEvents[stackArn] = []Event{}
+	}
+	if !slices.Contains(s.stacks, stackArn) {
+		s.stacks = append(s.stacks, stackArn)
+	}
+}
+
+func (s *Snapshot) addNestedStackArn(parentArn, stackArn string) {
+	if slices.Contains(s.stacks, stackArn) || !slices.Contains(s.stacks, parentArn) {
+		return
+	}
+	if _, ok := s.stackEvents[stackArn]; !ok {
+		s.stackEvents[stackArn] = []Event{}
+	}
+	s.parents[stackArn] = parentArn
+	parentDepth := s.GetStackDepth(parentArn)
+	index := slices.Index(s.stacks, parentArn) + 1
+	for index < len(s.stacks) && s.GetStackDepth(s.stacks[index]) > parentDepth {
+		index++
+	}
+	s.stacks = slices.Insert(s.stacks, index, stackArn)
+}
+
+func (s *Snapshot) GetStackParent(stackArn string) string {
+	return s.parents[stackArn]
+}
+
+func (s *Snapshot) GetStackChildren(stackArn string) []string {
+	children := []string{}
+	for _, arn := range s.stacks {
+		if s.parents[arn] == stackArn {
+			children = append(children, arn)
+		}
+	}
+	return children
+}
+
+func (s *Snapshot) GetStackDepth(stackArn string) int {
+	depth := 0
+	for parentArn, ok := s.parents[stackArn]; ok; parentArn, ok = s.parents[parentArn] {
+		depth++
+	}
+	return depth
+}
+
+func (s *Snapshot) GetStackError(stackArn string) error {
+	return errors.Join(s.errors[stackArn], s.nestedErrors[stackArn])
+}
+
+func (s *Snapshot) GetStackTemplateError(stackArn string) error {
+	return s.templateErrors[stackArn]
+}
+
+func (s *Snapshot) GetStackDependencies(stackArn string) Dependencies {
+	return s.dependencies[stackArn]
+}
+
+func (s *Snapshot) GetStackEvents(stackArn string) []Event {
+	return s.stackEvents[stackArn]
+}
+
+func (s *Snapshot) GetAllStackEvents() []Event {
+	allEvents := []Event{}
+	for _, events := range s.stackEvents {
+		allEvents = append(allEvents, events...)
+	}
+	return allEvents
+}
+
+func (s *Snapshot) GetLatestOperation(selectedStack string, allStacks bool) string {
+	latestEventId := ""
+	latestTimestamp := time.Unix(0, 0)
+	for _, operation := range s.GetOperations(selectedStack, allStacks) {
+		if operation.Timestamp.After(latestTimestamp) {
+			latestEventId = operation.EventId
+			latestTimestamp = operation.Timestamp
+		}
+	}
+	return latestEventId
+}
+
+func (s *Snapshot) GetStackArns() []string {
+	return s.stacks
+}
+
+func (s *Snapshot) GetOperations(stackArn string, allStacks bool) []Event {
+	ops := []Event{}
+	for _, event := range s.operations {
+		if event.StackId == stackArn || allStacks {
+			ops = append(ops, event)
+		}
+	}
+	return ops
+}
+
+func (s *Snapshot) GetSortedIntervals(selectedStack, selectedOperation string, allStacks, allOperations bool) []Interval {
+	allIntervals := []Interval{}
+	for _, operation := range s.operations {
+		if allStacks || operation.StackId == selectedStack {
+			if allOperations || operation.EventId == selectedOperation {
+				intervals := s.StackIntervals.GetIntervals(operation.StackId, operation.EventId)
+				allIntervals = append(allIntervals, intervals...)
+			}
+		}
+	}
+	return allIntervals
+}
+
+func (s *Snapshot) hasOpenIntervals(stackArn string) bool {
+	for _, event := range s.stackEvents[stackArn] {
+		if event.IsOperation() {
+			for _, interval := range s.StackIntervals.GetIntervals(stackArn, event.EventId) {
+				if interval.IsOpen() {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return false
+}
+
+func (s *Snapshot) GetOperationIntervals(operation Event) []Interval {
+	return s.StackIntervals.GetIntervals(operation.StackId, operation.EventId)
+}
+
+func (s *Snapshot) GetOperationInterval(operation Event) *Interval {
+	for _, interval := range s.GetOperationIntervals(operation) {
+		if interval.Start.EventId == operation.EventId {
+			return &interval
+		}
+	}
+	return nil
+}
+
+func (s *Snapshot) GetIntervalOperation(interval Interval) (Event, bool) {
+	for _, operation := range s.GetOperations(interval.Start.StackId, false) {
+		for _, candidate := range s.GetOperationIntervals(operation) {
+			if candidate.Start.EventId == interval.Start.EventId {
+				return operation, true
+			}
+		}
+	}
+	return Event{}, false
+}
+
+func (s *Snapshot) GetNestedOperations(operation Event) []Event {
+	nested := []Event{}
+	intervals := s.GetOperationIntervals(operation)
+	if len(intervals) == 0 {
+		return nested
+	}
+	windowInterval := GetWindowInterval(&intervals)
+	for _, childArn := range s.GetStackChildren(operation.StackId) {
+		for _, childOperation := range s.GetOperations(childArn, false) {
+			if !childOperation.Timestamp.Before(windowInterval.Start.Timestamp) && !childOperation.Timestamp.After(windowInterval.End.Timestamp) {
+				nested = append(nested, childOperation)
+			}
+		}
+	}
+	return nested
+}
+
+func (s *Snapshot) GetOperationTree(operation Event) []Event {
+	operations := []Event{operation}
+	for _, nestedOperation := range s.GetNestedOperations(operation) {
+		operations = append(operations, s.GetOperationTree(nestedOperation)...)
+	}
+	return operations
+}
+
+func (s *Snapshot) GetOperationTreeIntervals(operation Event) []Interval {
+	intervals := []Interval{}
+	for _, treeOperation := range s.GetOperationTree(operation) {
+		intervals = append(intervals, s.GetOperationIntervals(treeOperation)...)
+	}
+	return intervals
+}
@@ -0,0 +1,162 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	maxRetryAttempts = 10
+	maxRetryBackoff  = 20 * time.Second
+)
+
+type EventSource interface {
+	ListStackEvents(ctx context.Context, stackArn, cursor string) ([]Event, error)
+	ListNestedStacks(ctx context.Context, stackArn string) ([]string, error)
+	GetTemplate(ctx context.Context, stackArn string) (string, error)
+}
+
+type CloudFormationSource struct {
+	cfnClient *cloudformation.Client
+}
+
+func NewCloudFormationSource(cfg aws.Config) *CloudFormationSource {
+	retryer := retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+		o.StandardOptions = append(o.StandardOptions, func(so *retry.StandardOptions) {
+			so.MaxAttempts = maxRetryAttempts
+			so.MaxBackoff = maxRetryBackoff
+		})
+	})
+	return &CloudFormationSource{
+		cfnClient: cloudformation.NewFromConfig(cfg, func(o *cloudformation.Options) {
+			o.Retryer = retryer
+		}),
+	}
+}
+
+func (src *CloudFormationSource) ListStackEvents(ctx context.Context, stackArn, cursor string) ([]Event, error) {
+	events := []Event{}
+	params := cloudformation.DescribeStackEventsInput{StackName: aws.String(stackArn)}
+	paginator := cloudformation.NewDescribeStackEventsPaginator(src.cfnClient, &params)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, event := range output.StackEvents {
+			if cursor != "" && aws.ToString(event.EventId) == cursor {
+				return events, nil
+			}
+			events = append(events, Event{
+				EventId:              aws.ToString(event.EventId),
+				StackId:              aws.ToString(event.StackId),
+				StackName:            aws.ToString(event.StackName),
+				Timestamp:            aws.ToTime(event.Timestamp),
+				LogicalResourceId:    aws.ToString(event.LogicalResourceId),
+				PhysicalResourceId:   aws.ToString(event.PhysicalResourceId),
+				ResourceStatus:       event.ResourceStatus,
+				ResourceStatusReason: aws.ToString(event.ResourceStatusReason),
+				ResourceType:         aws.ToString(event.ResourceType),
+			})
+		}
+	}
+	return events, nil
+}
+
+func (src *CloudFormationSource) ListNestedStacks(ctx context.Context, stackArn string) ([]string, error) {
+	stackArns := []string{}
+	params := cloudformation.ListStackResourcesInput{StackName: aws.String(stackArn)}
+	paginator := cloudformation.NewListStackResourcesPaginator(src.cfnClient, &params)
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range response.StackResourceSummaries {
+			if aws.ToString(resource.ResourceType) == "AWS::CloudFormation::Stack" {
+				stackArns = append(stackArns, aws.ToString(resource.PhysicalResourceId))
+			}
+		}
+	}
+	return stackArns, nil
+}
+
+func (src *CloudFormationSource) GetStackArn(ctx context.Context, stackName string) (string, error) {
+	params := cloudformation.DescribeStacksInput{StackName: aws.String(stackName)}
+	response, err := src.cfnClient.DescribeStacks(ctx, &params)
+	if err != nil {
+		if isStackNotFoundErr(err) {
+			return "", StackNotFoundErr
+		}
+		return "", err
+	}
+	if len(response.Stacks) == 0 {
+		return "", StackNotFoundErr
+	}
+	return aws.ToString(response.Stacks[0].StackId), nil
+}
+
+func (src *CloudFormationSource) GetRootStackArn(ctx context.Context, stackArn string) (string, error) {
+	params := cloudformation.DescribeStacksInput{StackName: aws.String(stackArn)}
+	response, err := src.cfnClient.DescribeStacks(ctx, &params)
+	if err != nil {
+		return "", err
+	}
+	if len(response.Stacks) == 0 {
+		return "", StackNotFoundErr
+	}
+	if rootArn := aws.ToString(response.Stacks[0].RootId); rootArn != "" {
+		return rootArn, nil
+	}
+	return aws.ToString(response.Stacks[0].StackId), nil
+}
+
+func (src *CloudFormationSource) ListDeletedStacks(ctx context.Context, stackName string) ([]StackSummary, error) {
+	stacks := []StackSummary{}
+	params := cloudformation.ListStacksInput{StackStatusFilter: []types.StackStatus{types.StackStatusDeleteComplete}}
+	paginator := cloudformation.NewListStacksPaginator(src.cfnClient, &params)
+	for paginator.HasMorePages() {
+		response, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, stack := range response.StackSummaries {
+			if aws.ToString(stack.StackName) == stackName {
+				stacks = append(stacks, StackSummary{
+					StackId:      aws.ToString(stack.StackId),
+					StackName:    aws.ToString(stack.StackName),
+					CreationTime: aws.ToTime(stack.CreationTime),
+					DeletionTime: aws.ToTime(stack.DeletionTime),
+				})
+			}
+		}
+	}
+	slices.SortFunc(stacks, func(a, b StackSummary) int { return b.DeletionTime.Compare(a.DeletionTime) })
+	return stacks, nil
+}
+
+func (src *CloudFormationSource) GetTemplate(ctx context.Context, stackArn string) (string, error) {
+	params := cloudformation.GetTemplateInput{StackName: aws.String(stackArn), TemplateStage: types.TemplateStageProcessed}
+	response, err := src.cfnClient.GetTemplate(ctx, &params)
+	if err != nil {
+		return "", err
+	}
+	return aws.ToString(response.TemplateBody), nil
+}
+
+func isStackNotFoundErr(err error) bool {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode() == "ValidationError" && strings.Contains(apiErr.ErrorMessage(), "does not exist")
+	}
+	return false
+}
@@ -0,0 +1,12 @@
+package aws
+
+import (
+	"time"
+)
+
+type StackSummary struct {
+	StackId      string
+	StackName    string
+	CreationTime time.Time
+	DeletionTime time.Time
+}
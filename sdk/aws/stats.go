@@ -0,0 +1,132 @@
+package aws
+
+import (
+	"math"
+	"strings"
+	"time"
+
+	"golang.org/x/exp/slices"
+)
+
+type OperationStats struct {
+	Operation      Event
+	IntervalCount  int
+	Duration       time.Duration
+	TotalDuration  time.Duration
+	MedianDuration time.Duration
+	ActionCounts   map[string]int
+	OutcomeCounts  map[Outcome]int
+	ResourceTypes  []ResourceTypeStats
+}
+
+type ResourceTypeStats struct {
+	ResourceType string
+	Count        int
+	Total        time.Duration
+	P50          time.Duration
+	P90          time.Duration
+	P99          time.Duration
+	Max          time.Duration
+}
+
+func (s *Snapshot) GetOperationStats(operation Event) OperationStats {
+	intervals := s.GetOperationTreeIntervals(operation)
+	stats := OperationStats{
+		Operation:     operation,
+		ActionCounts:  map[string]int{},
+		OutcomeCounts: map[Outcome]int{},
+		ResourceTypes: []ResourceTypeStats{},
+	}
+	if len(intervals) == 0 {
+		return stats
+	}
+	windowInterval := GetWindowInterval(&intervals)
+	stats.Duration = windowInterval.End.Timestamp.Sub(windowInterval.Start.Timestamp)
+	durations := []time.Duration{}
+	typeDurations := map[string][]time.Duration{}
+	for _, interval := range intervals {
+		if interval.Start.IsOperation() {
+			continue
+		}
+		stats.IntervalCount++
+		duration := interval.Duration()
+		durations = append(durations, duration)
+		typeDurations[interval.Start.ResourceType] = append(typeDurations[interval.Start.ResourceType], duration)
+		stats.TotalDuration += duration
+		stats.ActionCounts[GetIntervalAction(interval)]++
+		stats.OutcomeCounts[interval.Outcome()]++
+	}
+	slices.Sort(durations)
+	stats.MedianDuration = getMedian(durations)
+	for resourceType, durations := range typeDurations {
+		slices.Sort(durations)
+		typeStats := ResourceTypeStats{
+			ResourceType: resourceType,
+			Count:        len(durations),
+			P50:          getPercentile(durations, 50),
+			P90:          getPercentile(durations, 90),
+			P99:          getPercentile(durations, 99),
+			Max:          durations[len(durations)-1],
+		}
+		for _, duration := range durations {
+			typeStats.Total += duration
+		}
+		stats.ResourceTypes = append(stats.ResourceTypes, typeStats)
+	}
+	slices.SortFunc(stats.ResourceTypes, func(a, b ResourceTypeStats) int {
+		if a.Total != b.Total {
+			return int(b.Total - a.Total)
+		}
+		return strings.Compare(a.ResourceType, b.ResourceType)
+	})
+	return stats
+}
+
+func (s *Snapshot) GetSelectedStats(selection Selection) []OperationStats {
+	stats := []OperationStats{}
+	for _, operation := range s.GetSelectedRootOperations(selection) {
+		stats = append(stats, s.GetOperationStats(operation))
+	}
+	return stats
+}
+
+func (stats OperationStats) GetActions() []string {
+	actions := []string{}
+	for action := range stats.ActionCounts {
+		actions = append(actions, action)
+	}
+	slices.Sort(actions)
+	return actions
+}
+
+func GetIntervalAction(interval Interval) string {
+	status := string(interval.Start.ResourceStatus)
+	for _, suffix := range []string{"_IN_PROGRESS", "_COMPLETE", "_FAILED"} {
+		if action, ok := strings.CutSuffix(status, suffix); ok {
+			return action
+		}
+	}
+	return status
+}
+
+func getMedian(sorted []time.Duration) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+func getPercentile(sorted []time.Duration, percentile float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(percentile/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
@@ -0,0 +1,30 @@
+package aws
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestGetOperationStatsExcludesOperationInterval(t *testing.T) {
+	source := &fakeSource{events: map[string][]Event{testStackArn: newTestEvents()}, visible: map[string]int{testStackArn: 7}}
+	dataSet := NewDataSetFromSource(source, testStackArn)
+	if err := dataSet.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	snapshot := dataSet.Snapshot()
+	operation, ok := snapshot.GetOperation("event-01")
+	if !ok {
+		t.Fatalf("operation not found")
+	}
+	stats := snapshot.GetOperationStats(operation)
+	if stats.IntervalCount != 2 {
+		t.Fatalf("expected 2 intervals, got %d", stats.IntervalCount)
+	}
+	if stats.Duration != 6*time.Second || stats.TotalDuration != 6*time.Second {
+		t.Fatalf("expected 6s duration and total, got %s and %s", stats.Duration, stats.TotalDuration)
+	}
+	if len(stats.ResourceTypes) != 1 || stats.ResourceTypes[0].Count != 2 {
+		t.Fatalf("expected a single resource type with 2 intervals, got %+v", stats.ResourceTypes)
+	}
+}
@@ -0,0 +1,159 @@
+package aws
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/exp/slices"
+	"gopkg.in/yaml.v3"
+)
+
+type Dependencies map[string][]string
+
+var subVariableRegexp = regexp.MustCompile(`\$\{([^!}][^}]*)\}`)
+
+func ParseTemplateDependencies(body string) (Dependencies, error) {
+	parsed, err := parseTemplate(body)
+	if err != nil {
+		return nil, err
+	}
+	template, ok := parsed.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("template is not a mapping")
+	}
+	resources, ok := template["Resources"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("template has no resources")
+	}
+	dependencies := Dependencies{}
+	for logicalId, resource := range resources {
+		found := map[string]bool{}
+		if resource, ok := resource.(map[string]any); ok {
+			switch dependsOn := resource["DependsOn"].(type) {
+			case string:
+				found[dependsOn] = true
+			case []any:
+				for _, value := range dependsOn {
+					if value, ok := value.(string); ok {
+						found[value] = true
+					}
+				}
+			}
+			findReferences(resource["Properties"], found)
+		}
+		dependencies[logicalId] = []string{}
+		for reference := range found {
+			if _, ok := resources[reference]; ok && reference != logicalId {
+				dependencies[logicalId] = append(dependencies[logicalId], reference)
+			}
+		}
+		slices.Sort(dependencies[logicalId])
+	}
+	return dependencies, nil
+}
+
+func (d Dependencies) DependsOn(logicalId, target string) bool {
+	return slices.Contains(d[logicalId], target)
+}
+
+func parseTemplate(body string) (any, error) {
+	if strings.HasPrefix(strings.TrimSpace(body), "{") {
+		var parsed any
+		err := json.Unmarshal([]byte(body), &parsed)
+		return parsed, err
+	}
+	root := yaml.Node{}
+	if err := yaml.Unmarshal([]byte(body), &root); err != nil {
+		return nil, err
+	}
+	if len(root.Content) == 0 {
+		return nil, fmt.Errorf("template is empty")
+	}
+	return convertTemplateNode(&root), nil
+}
+
+func findReferences(value any, found map[string]bool) {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, child := range value {
+			switch key {
+			case "Ref":
+				if name, ok := child.(string); ok {
+					found[name] = true
+				}
+			case "Fn::GetAtt":
+				switch target := child.(type) {
+				case string:
+					name, _, _ := strings.Cut(target, ".")
+					found[name] = true
+				case []any:
+					if len(target) > 0 {
+						if name, ok := target[0].(string); ok {
+							found[name] = true
+						}
+					}
+				}
+			case "Fn::Sub":
+				template := child
+				if list, ok := child.([]any); ok && len(list) > 0 {
+					template = list[0]
+				}
+				if template, ok := template.(string); ok {
+					for _, match := range subVariableRegexp.FindAllStringSubmatch(template, -1) {
+						name, _, _ := strings.Cut(match[1], ".")
+						found[name] = true
+					}
+				}
+			}
+			findReferences(child, found)
+		}
+	case []any:
+		for _, child := range value {
+			findReferences(child, found)
+		}
+	}
+}
+
+func convertTemplateNode(node *yaml.Node) any {
+	var value any
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			return convertTemplateNode(node.Content[0])
+		}
+		return nil
+	case yaml.AliasNode:
+		return convertTemplateNode(node.Alias)
+	case yaml.MappingNode:
+		mapping := map[string]any{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			mapping[node.Content[i].Value] = convertTemplateNode(node.Content[i+1])
+		}
+		value = mapping
+	case yaml.SequenceNode:
+		sequence := []any{}
+		for _, child := range node.Content {
+			sequence = append(sequence, convertTemplateNode(child))
+		}
+		value = sequence
+	default:
+		value = node.Value
+	}
+	if !strings.HasPrefix(node.Tag, "!") || strings.HasPrefix(node.Tag, "!!") {
+		return value
+	}
+	function := strings.TrimPrefix(node.Tag, "!")
+	if function == "Ref" || function == "Condition" {
+		return map[string]any{function: value}
+	}
+	if function == "GetAtt" {
+		if name, ok := value.(string); ok {
+			if resource, attribute, ok := strings.Cut(name, "."); ok {
+				value = []any{resource, attribute}
+			}
+		}
+	}
+	return map[string]any{"Fn::" + function: value}
+}
@@ -0,0 +1,105 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseTemplateDependencies(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected Dependencies
+	}{
+		{
+			name: "json references",
+			body: `{
+				"Parameters": {"Name": {"Type": "String"}},
+				"Resources": {
+					"Bucket": {"Type": "AWS::S3::Bucket", "Properties": {"BucketName": {"Ref": "Name"}}},
+					"Queue": {"Type": "AWS::SQS::Queue", "DependsOn": "Bucket"},
+					"Topic": {"Type": "AWS::SNS::Topic", "DependsOn": ["Bucket", "Queue"]},
+					"Policy": {"Type": "AWS::SNS::TopicPolicy", "Properties": {
+						"Topics": [{"Ref": "Topic"}],
+						"Queue": {"Fn::GetAtt": ["Queue", "Arn"]},
+						"Name": {"Fn::Sub": "${Bucket}-${AWS::Region}-${!Literal}"}
+					}}
+				}
+			}`,
+			expected: Dependencies{
+				"Bucket": {},
+				"Queue":  {"Bucket"},
+				"Topic":  {"Bucket", "Queue"},
+				"Policy": {"Bucket", "Queue", "Topic"},
+			},
+		},
+		{
+			name: "yaml short form tags",
+			body: `
+Parameters:
+  Name:
+    Type: String
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !Ref Name
+  Queue:
+    Type: AWS::SQS::Queue
+    DependsOn: Bucket
+  Topic:
+    Type: AWS::SNS::Topic
+    DependsOn:
+      - Queue
+    Properties:
+      DisplayName: !GetAtt Bucket.Arn
+  Policy:
+    Type: AWS::SNS::TopicPolicy
+    Properties:
+      Topics:
+        - !Ref Topic
+      Name: !Sub
+        - "${Prefix}-${Queue.Arn}"
+        - Prefix: !Ref Bucket
+`,
+			expected: Dependencies{
+				"Bucket": {},
+				"Queue":  {"Bucket"},
+				"Topic":  {"Bucket", "Queue"},
+				"Policy": {"Bucket", "Queue", "Topic"},
+			},
+		},
+		{
+			name: "self references are ignored",
+			body: `{"Resources": {"Bucket": {"Type": "AWS::S3::Bucket", "DependsOn": "Bucket", "Properties": {"Name": {"Ref": "Bucket"}}}}}`,
+			expected: Dependencies{
+				"Bucket": {},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dependencies, err := ParseTemplateDependencies(test.body)
+			if err != nil {
+				t.Fatalf("parse failed: %v", err)
+			}
+			if !reflect.DeepEqual(dependencies, test.expected) {
+				t.Fatalf("expected %v, got %v", test.expected, dependencies)
+			}
+		})
+	}
+}
+
+func TestParseTemplateDependenciesErrors(t *testing.T) {
+	for name, body := range map[string]string{
+		"invalid json":  `{"Resources": `,
+		"invalid yaml":  "Resources: [",
+		"empty":         "",
+		"not a mapping": "- Bucket",
+		"no resources":  `{"Parameters": {}}`,
+	} {
+		if _, err := ParseTemplateDependencies(body); err == nil {
+			t.Errorf("expected error for %s", name)
+		}
+	}
+}
@@ -12,6 +12,10 @@ func ExtractStackNameFromArn(arn string) string {
 	return ""
 }
 
+func IsStackArn(value string) bool {
+	return strings.HasPrefix(value, "arn:") && strings.Contains(value, ":stack/")
+}
+
 func GetWindowInterval(intervals *[]Interval) Interval {
 	windowInterval := Interval{}
 	for _, interval := range *intervals {
@@ -0,0 +1,112 @@
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+type csvRecord struct {
+	operation aws.Event
+	interval  aws.Interval
+	critical  bool
+}
+
+type csvColumn func(record csvRecord) string
+
+var CsvColumns = []string{
+	"stack_name",
+	"operation_event_id",
+	"operation_timestamp",
+	"logical_resource_id",
+	"resource_type",
+	"physical_resource_id",
+	"start",
+	"end",
+	"duration_seconds",
+	"status",
+	"status_reason",
+	"critical_path",
+}
+
+var csvColumns = map[string]csvColumn{
+	"stack_name": func(record csvRecord) string {
+		return record.interval.Start.StackName
+	},
+	"operation_event_id": func(record csvRecord) string {
+		return record.operation.EventId
+	},
+	"operation_timestamp": func(record csvRecord) string {
+		return record.operation.Timestamp.UTC().Format(time.RFC3339)
+	},
+	"logical_resource_id": func(record csvRecord) string {
+		return record.interval.Start.LogicalResourceId
+	},
+	"resource_type": func(record csvRecord) string {
+		return record.interval.Start.ResourceType
+	},
+	"physical_resource_id": func(record csvRecord) string {
+		if record.interval.End.PhysicalResourceId != "" {
+			return record.interval.End.PhysicalResourceId
+		}
+		return record.interval.Start.PhysicalResourceId
+	},
+	"start": func(record csvRecord) string {
+		return record.interval.Start.Timestamp.UTC().Format(time.RFC3339)
+	},
+	"end": func(record csvRecord) string {
+		if record.interval.IsOpen() {
+			return ""
+		}
+		return record.interval.End.Timestamp.UTC().Format(time.RFC3339)
+	},
+	"duration_seconds": func(record csvRecord) string {
+		return strconv.FormatFloat(record.interval.Duration().Seconds(), 'f', 0, 64)
+	},
+	"status": func(record csvRecord) string {
+		return string(record.interval.End.ResourceStatus)
+	},
+	"status_reason": func(record csvRecord) string {
+		return record.interval.End.ResourceStatusReason
+	},
+	"critical_path": func(record csvRecord) string {
+		return strconv.FormatBool(record.critical)
+	},
+}
+
+func WriteCsv(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection, separator rune, columns []string) error {
+	if len(columns) == 0 {
+		columns = CsvColumns
+	}
+	for _, column := range columns {
+		if _, ok := csvColumns[column]; !ok {
+			return fmt.Errorf("unknown column %q", column)
+		}
+	}
+	writer := csv.NewWriter(w)
+	writer.Comma = separator
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+	criticalPath := getCriticalPathEventIds(snapshot, selection)
+	for _, rootOperation := range snapshot.GetSelectedRootOperations(selection) {
+		for _, operation := range snapshot.GetOperationTree(rootOperation) {
+			for _, interval := range snapshot.GetOperationIntervals(operation) {
+				record := csvRecord{operation: operation, interval: interval, critical: criticalPath[interval.Start.EventId]}
+				values := []string{}
+				for _, column := range columns {
+					values = append(values, csvColumns[column](record))
+				}
+				if err := writer.Write(values); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
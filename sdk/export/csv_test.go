@@ -0,0 +1,34 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"testing"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+func TestWriteCsvIncludesNestedStacks(t *testing.T) {
+	snapshot := newTestSnapshot(t)
+	selection := aws.Selection{Stack: testRootArn, Operation: snapshot.GetLatestOperation(testRootArn, false)}
+	output := bytes.Buffer{}
+	if err := WriteCsv(&output, snapshot, selection, ',', []string{"stack_name", "logical_resource_id"}); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	records, err := csv.NewReader(&output).ReadAll()
+	if err != nil {
+		t.Fatalf("read failed: %v", err)
+	}
+	want := [][]string{
+		{"stack_name", "logical_resource_id"},
+		{"test", "Child"},
+		{"test", "Bucket"},
+		{"test", "test"},
+		{"test-child", "Topic"},
+		{"test-child", "test-child"},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Fatalf("expected %v, got %v", want, records)
+	}
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/null93/waterfall/sdk/aws"
+	"github.com/null93/waterfall/sdk/gui"
+)
+
+func WriteDiff(w io.Writer, snapshot *aws.Snapshot, from, to aws.Event) error {
+	fmt.Fprintf(w, "From: %s (%s)\n", getOperationTitle(from), from.EventId)
+	fmt.Fprintf(w, "To:   %s (%s)\n\n", getOperationTitle(to), to.EventId)
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "\tSTACK\tLOGICAL RESOURCE ID\tFROM START\tTO START\tSTART DELTA\tFROM DURATION\tTO DURATION\tDURATION DELTA")
+	for _, comparison := range snapshot.CompareOperations(from, to) {
+		fromStart, fromDuration, toStart, toDuration := "-", "-", "-", "-"
+		startDelta, durationDelta := "-", "-"
+		marker := " "
+		if comparison.From != nil {
+			fromStart = "+" + comparison.FromOffset.Round(time.Second).String()
+			fromDuration = comparison.From.Duration().Round(time.Second).String()
+		}
+		if comparison.To != nil {
+			toStart = "+" + comparison.ToOffset.Round(time.Second).String()
+			toDuration = comparison.To.Duration().Round(time.Second).String()
+		}
+		switch {
+		case comparison.IsFromOnly():
+			marker = "-"
+		case comparison.IsToOnly():
+			marker = "+"
+		default:
+			startDelta = gui.FormatDelta(comparison.OffsetDelta())
+			durationDelta = gui.FormatDelta(comparison.DurationDelta())
+		}
+		fmt.Fprintf(
+			table,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			marker,
+			comparison.StackName,
+			comparison.LogicalResourceId,
+			fromStart,
+			toStart,
+			startDelta,
+			fromDuration,
+			toDuration,
+			durationDelta,
+		)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n-: only in from, +: only in to\n")
+	return err
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+const (
+	testRootArn  = "arn:aws:cloudformation:us-east-1:123456789012:stack/test/1"
+	testChildArn = "arn:aws:cloudformation:us-east-1:123456789012:stack/test-child/2"
+)
+
+type fakeSource struct {
+	events map[string][]aws.Event
+}
+
+func (f *fakeSource) ListStackEvents(ctx context.Context, stackArn, cursor string) ([]aws.Event, error) {
+	events := []aws.Event{}
+	for i := len(f.events[stackArn]) - 1; i >= 0 && f.events[stackArn][i].EventId != cursor; i-- {
+		events = append(events, f.events[stackArn][i])
+	}
+	return events, nil
+}
+
+func (f *fakeSource) ListNestedStacks(ctx context.Context, stackArn string) ([]string, error) {
+	return []string{}, nil
+}
+
+func (f *fakeSource) GetTemplate(ctx context.Context, stackArn string) (string, error) {
+	return `{"Resources": {}}`, nil
+}
+
+func newTestSnapshot(t *testing.T) *aws.Snapshot {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	event := func(second int, stackArn, logicalResourceId, resourceType, physicalResourceId string, status types.ResourceStatus, reason string) aws.Event {
+		return aws.Event{
+			EventId:              fmt.Sprintf("event-%02d", second),
+			StackId:              stackArn,
+			StackName:            aws.ExtractStackNameFromArn(stackArn),
+			Timestamp:            start.Add(time.Duration(second) * time.Second),
+			LogicalResourceId:    logicalResourceId,
+			PhysicalResourceId:   physicalResourceId,
+			ResourceType:         resourceType,
+			ResourceStatus:       status,
+			ResourceStatusReason: reason,
+		}
+	}
+	source := &fakeSource{events: map[string][]aws.Event{
+		testRootArn: {
+			event(0, testRootArn, "test", "AWS::CloudFormation::Stack", testRootArn, types.ResourceStatusCreateInProgress, "User Initiated"),
+			event(1, testRootArn, "Bucket", "AWS::S3::Bucket", "", types.ResourceStatusCreateInProgress, ""),
+			event(2, testRootArn, "Child", "AWS::CloudFormation::Stack", testChildArn, types.ResourceStatusCreateInProgress, ""),
+			event(5, testRootArn, "Bucket", "AWS::S3::Bucket", "bucket", types.ResourceStatusCreateComplete, ""),
+			event(9, testRootArn, "Child", "AWS::CloudFormation::Stack", testChildArn, types.ResourceStatusCreateComplete, ""),
+			event(10, testRootArn, "test", "AWS::CloudFormation::Stack", testRootArn, types.ResourceStatusCreateComplete, ""),
+		},
+		testChildArn: {
+			event(3, testChildArn, "test-child", "AWS::CloudFormation::Stack", testChildArn, types.ResourceStatusCreateInProgress, "User Initiated"),
+			event(4, testChildArn, "Topic", "AWS::SNS::Topic", "", types.ResourceStatusCreateInProgress, ""),
+			event(7, testChildArn, "Topic", "AWS::SNS::Topic", "topic", types.ResourceStatusCreateComplete, ""),
+			event(8, testChildArn, "test-child", "AWS::CloudFormation::Stack", testChildArn, types.ResourceStatusCreateComplete, ""),
+		},
+	}}
+	dataSet := aws.NewDataSetFromSource(source, testRootArn)
+	if err := dataSet.Refresh(context.Background()); err != nil {
+		t.Fatalf("refresh failed: %v", err)
+	}
+	return dataSet.Snapshot()
+}
@@ -0,0 +1,46 @@
+package export
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"io"
+
+	"github.com/null93/waterfall/sdk/aws"
+	"github.com/null93/waterfall/sdk/gui"
+)
+
+//go:embed templates/report.html
+var reportTemplate string
+
+type htmlReport struct {
+	Title     string
+	Document  JsonDocument
+	Colors    map[string]string
+	Selection aws.Selection
+}
+
+func WriteHtml(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection) error {
+	tmpl, err := template.New("report").Parse(reportTemplate)
+	if err != nil {
+		return err
+	}
+	report := htmlReport{
+		Title:     fmt.Sprintf("Waterfall: %s", aws.ExtractStackNameFromArn(snapshot.OriginalStackArn)),
+		Document:  NewJsonDocument(snapshot, aws.Selection{AllStacks: true, AllOperations: true}),
+		Colors:    map[string]string{},
+		Selection: selection,
+	}
+	for _, interval := range snapshot.GetSortedIntervals("", "", true, true) {
+		report.Colors[interval.Start.EventId] = getHexColor(interval)
+	}
+	return tmpl.Execute(w, report)
+}
+
+func getHexColor(interval aws.Interval) string {
+	hex := gui.GetIntervalColor(interval).Hex()
+	if hex < 0 {
+		return "#808080"
+	}
+	return fmt.Sprintf("#%06x", hex)
+}
@@ -0,0 +1,211 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+const (
+	JSON_VERSION = 1
+)
+
+type JsonDocument struct {
+	Version     int             `json:"version"`
+	GeneratedAt time.Time       `json:"generated_at"`
+	RootStack   string          `json:"root_stack"`
+	Stacks      []JsonStack     `json:"stacks"`
+	Operations  []JsonOperation `json:"operations"`
+}
+
+type JsonStack struct {
+	StackArn  string `json:"stack_arn"`
+	StackName string `json:"stack_name"`
+	ParentArn string `json:"parent_arn,omitempty"`
+	Depth     int    `json:"depth"`
+}
+
+type JsonOperation struct {
+	EventId              string         `json:"event_id"`
+	ParentEventId        string         `json:"parent_event_id,omitempty"`
+	StackArn             string         `json:"stack_arn"`
+	StackName            string         `json:"stack_name"`
+	Timestamp            time.Time      `json:"timestamp"`
+	ResourceStatus       string         `json:"resource_status"`
+	CriticalPath         []string       `json:"critical_path"`
+	CriticalPathFallback bool           `json:"critical_path_fallback"`
+	Stats                JsonStats      `json:"stats"`
+	Intervals            []JsonInterval `json:"intervals"`
+}
+
+type JsonStats struct {
+	IntervalCount         int                     `json:"interval_count"`
+	DurationSeconds       float64                 `json:"duration_seconds"`
+	TotalDurationSeconds  float64                 `json:"total_duration_seconds"`
+	MedianDurationSeconds float64                 `json:"median_duration_seconds"`
+	ActionCounts          map[string]int          `json:"action_counts"`
+	OutcomeCounts         map[aws.Outcome]int     `json:"outcome_counts"`
+	ResourceTypes         []JsonResourceTypeStats `json:"resource_types"`
+}
+
+type JsonResourceTypeStats struct {
+	ResourceType string  `json:"resource_type"`
+	Count        int     `json:"count"`
+	TotalSeconds float64 `json:"total_seconds"`
+	P50Seconds   float64 `json:"p50_seconds"`
+	P90Seconds   float64 `json:"p90_seconds"`
+	P99Seconds   float64 `json:"p99_seconds"`
+	MaxSeconds   float64 `json:"max_seconds"`
+}
+
+type JsonInterval struct {
+	StackName          string      `json:"stack_name"`
+	LogicalResourceId  string      `json:"logical_resource_id"`
+	PhysicalResourceId string      `json:"physical_resource_id"`
+	ResourceType       string      `json:"resource_type"`
+	Start              JsonEvent   `json:"start"`
+	Intermediate       []JsonEvent `json:"intermediate"`
+	End                *JsonEvent  `json:"end"`
+	DurationSeconds    float64     `json:"duration_seconds"`
+	Outcome            aws.Outcome `json:"outcome"`
+	Critical           bool        `json:"critical"`
+}
+
+type JsonEvent struct {
+	EventId              string    `json:"event_id"`
+	StackArn             string    `json:"stack_arn"`
+	Timestamp            time.Time `json:"timestamp"`
+	LogicalResourceId    string    `json:"logical_resource_id"`
+	PhysicalResourceId   string    `json:"physical_resource_id"`
+	ResourceType         string    `json:"resource_type"`
+	ResourceStatus       string    `json:"resource_status"`
+	ResourceStatusReason string    `json:"resource_status_reason"`
+}
+
+func NewJsonDocument(snapshot *aws.Snapshot, selection aws.Selection) JsonDocument {
+	document := JsonDocument{
+		Version:     JSON_VERSION,
+		GeneratedAt: time.Now().UTC(),
+		RootStack:   snapshot.OriginalStackArn,
+		Stacks:      []JsonStack{},
+		Operations:  []JsonOperation{},
+	}
+	for _, stackArn := range snapshot.GetStackArns() {
+		document.Stacks = append(document.Stacks, JsonStack{
+			StackArn:  stackArn,
+			StackName: aws.ExtractStackNameFromArn(stackArn),
+			ParentArn: snapshot.GetStackParent(stackArn),
+			Depth:     snapshot.GetStackDepth(stackArn),
+		})
+	}
+	criticalPath := getCriticalPathEventIds(snapshot, selection)
+	for _, operation := range snapshot.GetSelectedRootOperations(selection) {
+		document.Operations = appendJsonOperations(document.Operations, snapshot, operation, "", criticalPath)
+	}
+	return document
+}
+
+func WriteJson(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewJsonDocument(snapshot, selection))
+}
+
+func appendJsonOperations(operations []JsonOperation, snapshot *aws.Snapshot, operation aws.Event, parentEventId string, criticalPath map[string]bool) []JsonOperation {
+	jsonOperation := JsonOperation{
+		EventId:              operation.EventId,
+		ParentEventId:        parentEventId,
+		StackArn:             operation.StackId,
+		StackName:            operation.StackName,
+		Timestamp:            operation.Timestamp,
+		ResourceStatus:       string(operation.ResourceStatus),
+		CriticalPath:         []string{},
+		CriticalPathFallback: snapshot.IsCriticalPathFallback(operation),
+		Stats:                newJsonStats(snapshot.GetOperationStats(operation)),
+		Intervals:            []JsonInterval{},
+	}
+	for _, interval := range snapshot.GetCriticalPath(operation) {
+		jsonOperation.CriticalPath = append(jsonOperation.CriticalPath, interval.Start.EventId)
+	}
+	for _, interval := range snapshot.GetOperationIntervals(operation) {
+		jsonInterval := newJsonInterval(interval)
+		jsonInterval.Critical = criticalPath[interval.Start.EventId]
+		jsonOperation.Intervals = append(jsonOperation.Intervals, jsonInterval)
+	}
+	operations = append(operations, jsonOperation)
+	for _, nestedOperation := range snapshot.GetNestedOperations(operation) {
+		operations = appendJsonOperations(operations, snapshot, nestedOperation, operation.EventId, criticalPath)
+	}
+	return operations
+}
+
+func newJsonInterval(interval aws.Interval) JsonInterval {
+	jsonInterval := JsonInterval{
+		StackName:          interval.Start.StackName,
+		LogicalResourceId:  interval.Start.LogicalResourceId,
+		PhysicalResourceId: interval.Start.PhysicalResourceId,
+		ResourceType:       interval.Start.ResourceType,
+		Start:              newJsonEvent(interval.Start),
+		Intermediate:       []JsonEvent{},
+		DurationSeconds:    interval.Duration().Seconds(),
+		Outcome:            interval.Outcome(),
+	}
+	for _, event := range interval.Intermediate {
+		jsonInterval.Intermediate = append(jsonInterval.Intermediate, newJsonEvent(event))
+	}
+	if !interval.IsOpen() {
+		end := newJsonEvent(interval.End)
+		jsonInterval.End = &end
+		if end.PhysicalResourceId != "" {
+			jsonInterval.PhysicalResourceId = end.PhysicalResourceId
+		}
+	}
+	return jsonInterval
+}
+
+func newJsonStats(stats aws.OperationStats) JsonStats {
+	jsonStats := JsonStats{
+		IntervalCount:         stats.IntervalCount,
+		DurationSeconds:       stats.Duration.Seconds(),
+		TotalDurationSeconds:  stats.TotalDuration.Seconds(),
+		MedianDurationSeconds: stats.MedianDuration.Seconds(),
+		ActionCounts:          stats.ActionCounts,
+		OutcomeCounts:         stats.OutcomeCounts,
+		ResourceTypes:         []JsonResourceTypeStats{},
+	}
+	for _, typeStats := range stats.ResourceTypes {
+		jsonStats.ResourceTypes = append(jsonStats.ResourceTypes, JsonResourceTypeStats{
+			ResourceType: typeStats.ResourceType,
+			Count:        typeStats.Count,
+			TotalSeconds: typeStats.Total.Seconds(),
+			P50Seconds:   typeStats.P50.Seconds(),
+			P90Seconds:   typeStats.P90.Seconds(),
+			P99Seconds:   typeStats.P99.Seconds(),
+			MaxSeconds:   typeStats.Max.Seconds(),
+		})
+	}
+	return jsonStats
+}
+
+func getCriticalPathEventIds(snapshot *aws.Snapshot, selection aws.Selection) map[string]bool {
+	eventIds := map[string]bool{}
+	for _, interval := range snapshot.GetSelectedCriticalPath(selection) {
+		eventIds[interval.Start.EventId] = true
+	}
+	return eventIds
+}
+
+func newJsonEvent(event *aws.Event) JsonEvent {
+	return JsonEvent{
+		EventId:              event.EventId,
+		StackArn:             event.StackId,
+		Timestamp:            event.Timestamp,
+		LogicalResourceId:    event.LogicalResourceId,
+		PhysicalResourceId:   event.PhysicalResourceId,
+		ResourceType:         event.ResourceType,
+		ResourceStatus:       string(event.ResourceStatus),
+		ResourceStatusReason: event.ResourceStatusReason,
+	}
+}
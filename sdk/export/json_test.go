@@ -0,0 +1,34 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+func TestNewJsonDocumentIncludesNestedOperations(t *testing.T) {
+	snapshot := newTestSnapshot(t)
+	rootOperation := snapshot.GetLatestOperation(testRootArn, false)
+	selections := map[string]aws.Selection{
+		"selected operation": {Stack: testRootArn, Operation: rootOperation},
+		"all stacks":         {Stack: testRootArn, AllStacks: true, AllOperations: true},
+	}
+	for name, selection := range selections {
+		t.Run(name, func(t *testing.T) {
+			document := NewJsonDocument(snapshot, selection)
+			if len(document.Operations) != 2 {
+				t.Fatalf("expected 2 operations, got %d", len(document.Operations))
+			}
+			root, nested := document.Operations[0], document.Operations[1]
+			if root.EventId != rootOperation || root.ParentEventId != "" {
+				t.Fatalf("expected root operation %s without parent, got %s with parent %q", rootOperation, root.EventId, root.ParentEventId)
+			}
+			if nested.StackArn != testChildArn || nested.ParentEventId != rootOperation {
+				t.Fatalf("expected nested operation on %s with parent %s, got %s with parent %q", testChildArn, rootOperation, nested.StackArn, nested.ParentEventId)
+			}
+			if intervalCount := len(root.Intervals) + len(nested.Intervals); intervalCount != 5 {
+				t.Fatalf("expected 5 intervals, got %d", intervalCount)
+			}
+		})
+	}
+}
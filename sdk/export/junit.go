@@ -0,0 +1,124 @@
+package export
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+type JunitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Failures   int              `xml:"failures,attr"`
+	Skipped    int              `xml:"skipped,attr"`
+	Time       string           `xml:"time,attr"`
+	TestSuites []JunitTestSuite `xml:"testsuite"`
+}
+
+type JunitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Id        string          `xml:"id,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []JunitTestCase `xml:"testcase"`
+}
+
+type JunitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *JunitFailure `xml:"failure,omitempty"`
+	Skipped   *JunitSkipped `xml:"skipped,omitempty"`
+}
+
+type JunitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type JunitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+func NewJunitDocument(snapshot *aws.Snapshot, selection aws.Selection) JunitTestSuites {
+	document := JunitTestSuites{Name: aws.ExtractStackNameFromArn(snapshot.OriginalStackArn), TestSuites: []JunitTestSuite{}}
+	totalTime := time.Duration(0)
+	for _, rootOperation := range snapshot.GetSelectedRootOperations(selection) {
+		if interval := snapshot.GetOperationInterval(rootOperation); interval != nil {
+			totalTime += interval.Duration()
+		}
+		for _, operation := range snapshot.GetOperationTree(rootOperation) {
+			suite := newJunitTestSuite(snapshot, operation)
+			document.TestSuites = append(document.TestSuites, suite)
+			document.Tests += suite.Tests
+			document.Failures += suite.Failures
+			document.Skipped += suite.Skipped
+		}
+	}
+	document.Time = formatJunitTime(totalTime)
+	return document
+}
+
+func newJunitTestSuite(snapshot *aws.Snapshot, operation aws.Event) JunitTestSuite {
+	suiteTime := time.Duration(0)
+	suite := JunitTestSuite{
+		Name:      operation.StackName,
+		Id:        operation.EventId,
+		Timestamp: operation.Timestamp.UTC().Format("2006-01-02T15:04:05"),
+		TestCases: []JunitTestCase{},
+	}
+	if interval := snapshot.GetOperationInterval(operation); interval != nil {
+		suiteTime = interval.Duration()
+	}
+	for _, interval := range snapshot.GetOperationIntervals(operation) {
+		if interval.Start.IsOperation() {
+			continue
+		}
+		testCase := JunitTestCase{
+			Name:      interval.Start.LogicalResourceId,
+			ClassName: interval.Start.ResourceType,
+			Time:      formatJunitTime(interval.Duration()),
+		}
+		switch interval.Outcome() {
+		case aws.OUTCOME_FAILED:
+			testCase.Failure = &JunitFailure{
+				Message: interval.End.ResourceStatusReason,
+				Type:    string(interval.End.ResourceStatus),
+				Text:    interval.End.ResourceStatusReason,
+			}
+			suite.Failures++
+		case aws.OUTCOME_IN_PROGRESS:
+			testCase.Skipped = &JunitSkipped{Message: string(interval.End.ResourceStatus)}
+			suite.Skipped++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+		suite.Tests++
+	}
+	suite.Time = formatJunitTime(suiteTime)
+	return suite
+}
+
+func WriteJunit(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(NewJunitDocument(snapshot, selection)); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func formatJunitTime(duration time.Duration) string {
+	return strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)
+}
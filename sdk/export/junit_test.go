@@ -0,0 +1,32 @@
+package export
+
+import (
+	"testing"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+func TestNewJunitDocumentHasSuitePerStack(t *testing.T) {
+	snapshot := newTestSnapshot(t)
+	selection := aws.Selection{Stack: testRootArn, Operation: snapshot.GetLatestOperation(testRootArn, false)}
+	document := NewJunitDocument(snapshot, selection)
+	suites := map[string][]string{}
+	for _, suite := range document.TestSuites {
+		for _, testCase := range suite.TestCases {
+			suites[suite.Name] = append(suites[suite.Name], testCase.Name)
+		}
+	}
+	if len(document.TestSuites) != 2 || len(suites["test"]) != 2 || len(suites["test-child"]) != 1 {
+		t.Fatalf("expected suites for test and test-child, got %v", suites)
+	}
+	for name, testCases := range suites {
+		for _, testCase := range testCases {
+			if testCase == name {
+				t.Fatalf("suite %s contains its own stack as a test case", name)
+			}
+		}
+	}
+	if document.Tests != 3 || document.Time != "10.000" {
+		t.Fatalf("expected 3 tests over 10s, got %d over %s", document.Tests, document.Time)
+	}
+}
@@ -0,0 +1,186 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/null93/waterfall/sdk/aws"
+	"github.com/null93/waterfall/sdk/gui"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	MARKDOWN_WATERFALL_WIDTH = 60
+	MARKDOWN_LABEL_WIDTH     = 40
+)
+
+func WriteMarkdown(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection, top int) error {
+	operations := snapshot.GetSelectedOperations(selection)
+	if len(operations) == 0 {
+		return fmt.Errorf("no operation selected")
+	}
+	operation := operations[0]
+	intervals := snapshot.GetOperationTreeIntervals(operation)
+	if len(intervals) == 0 {
+		return fmt.Errorf("no intervals found for operation %s", operation.EventId)
+	}
+	windowInterval := aws.GetWindowInterval(&intervals)
+	md := strings.Builder{}
+
+	fmt.Fprintf(&md, "## %s\n\n", getOperationTitle(operation))
+	fmt.Fprintf(&md, "| | |\n|---|---|\n")
+	if operationInterval := snapshot.GetOperationInterval(operation); operationInterval != nil {
+		fmt.Fprintf(&md, "| Outcome | %s (`%s`) |\n", operationInterval.Outcome(), operationInterval.End.ResourceStatus)
+	}
+	fmt.Fprintf(&md, "| Duration | %s |\n", windowInterval.End.Timestamp.Sub(windowInterval.Start.Timestamp).Round(time.Second))
+	stats := snapshot.GetOperationStats(operation)
+	fmt.Fprintf(&md, "| Interval Count | %d |\n", stats.IntervalCount)
+	fmt.Fprintf(&md, "| Operation | `%s` |\n\n", operation.EventId)
+
+	fmt.Fprintf(&md, "### Statistics\n\n")
+	fmt.Fprintf(&md, "| | |\n|---|---|\n")
+	fmt.Fprintf(&md, "| Total Duration | %s |\n", stats.TotalDuration.Round(time.Second))
+	fmt.Fprintf(&md, "| Median Duration | %s |\n", stats.MedianDuration.Round(time.Second))
+	actionCounts := []string{}
+	for _, action := range stats.GetActions() {
+		actionCounts = append(actionCounts, fmt.Sprintf("%s %d", action, stats.ActionCounts[action]))
+	}
+	outcomeCounts := []string{}
+	for _, outcome := range []aws.Outcome{aws.OUTCOME_COMPLETE, aws.OUTCOME_FAILED, aws.OUTCOME_IN_PROGRESS} {
+		outcomeCounts = append(outcomeCounts, fmt.Sprintf("%s %d", outcome, stats.OutcomeCounts[outcome]))
+	}
+	fmt.Fprintf(&md, "| Actions | %s |\n", strings.Join(actionCounts, ", "))
+	fmt.Fprintf(&md, "| Outcomes | %s |\n", strings.Join(outcomeCounts, ", "))
+	md.WriteString("\n")
+	fmt.Fprintf(&md, "| Resource Type | Count | Total | P50 | P90 | P99 | Max |\n|---|---:|---:|---:|---:|---:|---:|\n")
+	for _, typeStats := range stats.ResourceTypes {
+		fmt.Fprintf(
+			&md,
+			"| %s | %d | %s | %s | %s | %s | %s |\n",
+			markdownEscape(typeStats.ResourceType),
+			typeStats.Count,
+			typeStats.Total.Round(time.Second),
+			typeStats.P50.Round(time.Second),
+			typeStats.P90.Round(time.Second),
+			typeStats.P99.Round(time.Second),
+			typeStats.Max.Round(time.Second),
+		)
+	}
+	md.WriteString("\n")
+
+	slowest := []aws.Interval{}
+	for _, interval := range intervals {
+		if !interval.Start.IsOperation() {
+			slowest = append(slowest, interval)
+		}
+	}
+	slices.SortStableFunc(slowest, func(a, b aws.Interval) int { return int(b.Duration() - a.Duration()) })
+	if top > 0 && len(slowest) > top {
+		slowest = slowest[:top]
+	}
+	fmt.Fprintf(&md, "### Slowest Resources\n\n")
+	fmt.Fprintf(&md, "| Stack | Logical Resource ID | Resource Type | Duration | Status |\n|---|---|---|---:|---|\n")
+	for _, interval := range slowest {
+		fmt.Fprintf(
+			&md,
+			"| %s | %s | %s | %s | %s |\n",
+			markdownEscape(interval.Start.StackName),
+			markdownEscape(interval.Start.LogicalResourceId),
+			markdownEscape(interval.Start.ResourceType),
+			interval.Duration().Round(time.Second),
+			interval.End.ResourceStatus,
+		)
+	}
+	md.WriteString("\n")
+
+	criticalPath := snapshot.GetCriticalPath(operation)
+	if len(criticalPath) > 0 {
+		fmt.Fprintf(&md, "### Critical Path\n\n")
+		if snapshot.IsCriticalPathFallback(operation) {
+			fmt.Fprintf(&md, "_Some templates could not be read, so this path follows timing only._\n\n")
+		}
+		fmt.Fprintf(&md, "_Dependencies are read from the current template of each stack, which may differ from the one this operation deployed._\n\n")
+		fmt.Fprintf(&md, "| # | Stack | Logical Resource ID | Resource Type | Offset | Duration |\n|---:|---|---|---|---:|---:|\n")
+		for i, interval := range criticalPath {
+			fmt.Fprintf(
+				&md,
+				"| %d | %s | %s | %s | %s | %s |\n",
+				i+1,
+				markdownEscape(interval.Start.StackName),
+				markdownEscape(interval.Start.LogicalResourceId),
+				markdownEscape(interval.Start.ResourceType),
+				interval.Start.Timestamp.Sub(windowInterval.Start.Timestamp).Round(time.Second),
+				interval.Duration().Round(time.Second),
+			)
+		}
+		md.WriteString("\n")
+	}
+
+	failed := []aws.Interval{}
+	for _, interval := range intervals {
+		if !interval.Start.IsOperation() && interval.Outcome() == aws.OUTCOME_FAILED {
+			failed = append(failed, interval)
+		}
+	}
+	slices.SortStableFunc(failed, func(a, b aws.Interval) int { return a.End.Timestamp.Compare(b.End.Timestamp) })
+	if len(failed) > 0 {
+		fmt.Fprintf(&md, "### Failed Resources\n\n")
+		fmt.Fprintf(&md, "| Stack | Logical Resource ID | Status | Reason |\n|---|---|---|---|\n")
+		for _, interval := range failed {
+			fmt.Fprintf(
+				&md,
+				"| %s | %s | %s | %s |\n",
+				markdownEscape(interval.Start.StackName),
+				markdownEscape(interval.Start.LogicalResourceId),
+				interval.End.ResourceStatus,
+				markdownEscape(interval.End.ResourceStatusReason),
+			)
+		}
+		md.WriteString("\n")
+	}
+
+	fmt.Fprintf(&md, "### Waterfall\n\n```\n")
+	for _, interval := range intervals {
+		fmt.Fprintf(&md, "%s %s\n", getMarkdownLabel(snapshot, interval), getTextBar(windowInterval, interval, MARKDOWN_WATERFALL_WIDTH))
+	}
+	md.WriteString("```\n")
+
+	_, err := io.WriteString(w, md.String())
+	return err
+}
+
+func getMarkdownLabel(snapshot *aws.Snapshot, interval aws.Interval) string {
+	label := strings.Repeat("  ", snapshot.GetStackDepth(interval.Start.StackId)) + interval.Start.LogicalResourceId
+	if runes := []rune(label); len(runes) > MARKDOWN_LABEL_WIDTH {
+		label = string(runes[:MARKDOWN_LABEL_WIDTH-1]) + "…"
+	}
+	return fmt.Sprintf("%-*s", MARKDOWN_LABEL_WIDTH, label)
+}
+
+func getTextBar(windowInterval, interval aws.Interval, width int) string {
+	windowStart := windowInterval.Start.Timestamp
+	secondsInCol := windowInterval.End.Timestamp.Sub(windowStart).Seconds() / float64(width)
+	intervalEnd := time.Now()
+	if !interval.IsOpen() {
+		intervalEnd = interval.End.Timestamp
+	}
+	intervalRune := gui.GetIntervalRune(interval)
+	bar := []rune{}
+	carryOver := windowStart
+	for i := 0; i < width; i++ {
+		nextCarryOver := carryOver.Add(time.Duration(secondsInCol * float64(time.Second)))
+		if interval.Start.Timestamp.Before(nextCarryOver) && intervalEnd.After(carryOver) {
+			bar = append(bar, intervalRune)
+		} else {
+			bar = append(bar, '─')
+		}
+		carryOver = nextCarryOver
+	}
+	return string(bar)
+}
+
+func markdownEscape(text string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
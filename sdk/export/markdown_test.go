@@ -0,0 +1,23 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+func TestWriteMarkdownIntervalCount(t *testing.T) {
+	snapshot := newTestSnapshot(t)
+	selection := aws.Selection{Stack: testRootArn, Operation: snapshot.GetLatestOperation(testRootArn, false)}
+	output := bytes.Buffer{}
+	if err := WriteMarkdown(&output, snapshot, selection, 10); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	for _, line := range []string{"| Interval Count | 3 |", "| Actions | CREATE 3 |"} {
+		if !strings.Contains(output.String(), line) {
+			t.Fatalf("expected %q in report:\n%s", line, output.String())
+		}
+	}
+}
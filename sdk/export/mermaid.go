@@ -0,0 +1,86 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/null93/waterfall/sdk/aws"
+	"golang.org/x/exp/slices"
+)
+
+func WriteMermaid(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection) error {
+	operations := snapshot.GetSelectedOperations(selection)
+	if len(operations) == 0 {
+		return fmt.Errorf("no operation selected")
+	}
+	lines := []string{
+		"gantt",
+		fmt.Sprintf("    title %s", getOperationTitle(operations[0])),
+		"    dateFormat x",
+		"    axisFormat %H:%M:%S",
+	}
+	seen := map[string]bool{}
+	sections := []string{}
+	sectionTasks := map[string][]string{}
+	taskCount := 0
+	for _, operation := range operations {
+		for _, treeOperation := range snapshot.GetOperationTree(operation) {
+			if seen[treeOperation.EventId] {
+				continue
+			}
+			seen[treeOperation.EventId] = true
+			stackName := aws.ExtractStackNameFromArn(treeOperation.StackId)
+			if _, ok := sectionTasks[stackName]; !ok {
+				sections = append(sections, stackName)
+			}
+			intervals := snapshot.GetOperationIntervals(treeOperation)
+			slices.SortStableFunc(intervals, func(a, b aws.Interval) int { return a.Start.Timestamp.Compare(b.Start.Timestamp) })
+			for _, interval := range intervals {
+				taskCount++
+				end := time.Now()
+				if !interval.IsOpen() {
+					end = interval.End.Timestamp
+				}
+				sectionTasks[stackName] = append(sectionTasks[stackName], fmt.Sprintf(
+					"    %s :%s, t%d, %d, %d",
+					mermaidEscape(interval.Start.LogicalResourceId),
+					getMermaidTag(interval),
+					taskCount,
+					interval.Start.Timestamp.UnixMilli(),
+					end.UnixMilli(),
+				))
+			}
+		}
+	}
+	for _, section := range sections {
+		lines = append(lines, fmt.Sprintf("    section %s", mermaidEscape(section)))
+		lines = append(lines, sectionTasks[section]...)
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+func getOperationTitle(operation aws.Event) string {
+	return fmt.Sprintf(
+		"%s %s %s",
+		aws.ExtractStackNameFromArn(operation.StackId),
+		strings.TrimSuffix(string(operation.ResourceStatus), "_IN_PROGRESS"),
+		operation.Timestamp.UTC().Format(time.RFC3339),
+	)
+}
+
+func getMermaidTag(interval aws.Interval) string {
+	switch interval.Outcome() {
+	case aws.OUTCOME_FAILED:
+		return "crit"
+	case aws.OUTCOME_IN_PROGRESS:
+		return "active"
+	}
+	return "done"
+}
+
+func mermaidEscape(text string) string {
+	return strings.NewReplacer(":", " ", ";", " ", "#", " ").Replace(text)
+}
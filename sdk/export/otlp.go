@@ -0,0 +1,226 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+const (
+	OTLP_SCOPE_NAME         = "github.com/null93/waterfall"
+	OTLP_SPAN_KIND_INTERNAL = 1
+	OTLP_STATUS_CODE_OK     = 1
+	OTLP_STATUS_CODE_ERROR  = 2
+)
+
+type OtlpDocument struct {
+	ResourceSpans []OtlpResourceSpans `json:"resourceSpans"`
+}
+
+type OtlpResourceSpans struct {
+	Resource   OtlpResource     `json:"resource"`
+	ScopeSpans []OtlpScopeSpans `json:"scopeSpans"`
+}
+
+type OtlpResource struct {
+	Attributes []OtlpAttribute `json:"attributes"`
+}
+
+type OtlpScopeSpans struct {
+	Scope OtlpScope  `json:"scope"`
+	Spans []OtlpSpan `json:"spans"`
+}
+
+type OtlpScope struct {
+	Name string `json:"name"`
+}
+
+type OtlpSpan struct {
+	TraceId           string          `json:"traceId"`
+	SpanId            string          `json:"spanId"`
+	ParentSpanId      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []OtlpAttribute `json:"attributes"`
+	Events            []OtlpSpanEvent `json:"events,omitempty"`
+	Status            OtlpStatus      `json:"status"`
+}
+
+type OtlpSpanEvent struct {
+	TimeUnixNano string          `json:"timeUnixNano"`
+	Name         string          `json:"name"`
+	Attributes   []OtlpAttribute `json:"attributes"`
+}
+
+type OtlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type OtlpAttribute struct {
+	Key   string             `json:"key"`
+	Value OtlpAttributeValue `json:"value"`
+}
+
+type OtlpAttributeValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+func NewOtlpDocument(snapshot *aws.Snapshot, selection aws.Selection) OtlpDocument {
+	spans := []OtlpSpan{}
+	for _, operation := range snapshot.GetSelectedRootOperations(selection) {
+		traceId := otlpId("trace:"+operation.EventId, 16)
+		spans = appendOperationSpans(spans, snapshot, operation, traceId, "")
+	}
+	return OtlpDocument{
+		ResourceSpans: []OtlpResourceSpans{
+			{
+				Resource: OtlpResource{
+					Attributes: []OtlpAttribute{
+						otlpAttribute("service.name", "cloudformation"),
+						otlpAttribute("cloud.provider", "aws"),
+					},
+				},
+				ScopeSpans: []OtlpScopeSpans{
+					{Scope: OtlpScope{Name: OTLP_SCOPE_NAME}, Spans: spans},
+				},
+			},
+		},
+	}
+}
+
+func WriteOtlp(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection) error {
+	return json.NewEncoder(w).Encode(NewOtlpDocument(snapshot, selection))
+}
+
+func SendOtlp(ctx context.Context, endpoint string, headers map[string]string, snapshot *aws.Snapshot, selection aws.Selection) error {
+	tracesUrl, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if tracesUrl.Path == "" || tracesUrl.Path == "/" {
+		tracesUrl.Path = "/v1/traces"
+	}
+	body := bytes.Buffer{}
+	if err := WriteOtlp(&body, snapshot, selection); err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, tracesUrl.String(), &body)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		request.Header.Set(key, value)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+		return fmt.Errorf("otlp endpoint responded with %s: %s", response.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+func appendOperationSpans(spans []OtlpSpan, snapshot *aws.Snapshot, operation aws.Event, traceId, parentSpanId string) []OtlpSpan {
+	intervals := snapshot.GetOperationIntervals(operation)
+	if len(intervals) == 0 {
+		return spans
+	}
+	operationInterval := snapshot.GetOperationInterval(operation)
+	if operationInterval == nil {
+		windowInterval := aws.GetWindowInterval(&intervals)
+		operationInterval = &windowInterval
+	}
+	stackName := aws.ExtractStackNameFromArn(operation.StackId)
+	operationSpan := newOtlpSpan(*operationInterval, traceId, otlpId("span:"+operation.EventId, 8), parentSpanId)
+	operationSpan.Name = fmt.Sprintf("%s %s", stackName, strings.TrimSuffix(string(operation.ResourceStatus), "_IN_PROGRESS"))
+	operationSpan.Attributes = append(
+		operationSpan.Attributes,
+		otlpAttribute("aws.cloudformation.stack_id", operation.StackId),
+		otlpAttribute("aws.cloudformation.operation_id", operation.EventId),
+	)
+	spans = append(spans, operationSpan)
+	for _, interval := range intervals {
+		if interval.Start.EventId == operation.EventId {
+			continue
+		}
+		spans = append(spans, newOtlpSpan(interval, traceId, otlpId("span:"+interval.Start.EventId, 8), operationSpan.SpanId))
+	}
+	for _, nestedOperation := range snapshot.GetNestedOperations(operation) {
+		spans = appendOperationSpans(spans, snapshot, nestedOperation, traceId, operationSpan.SpanId)
+	}
+	return spans
+}
+
+func newOtlpSpan(interval aws.Interval, traceId, spanId, parentSpanId string) OtlpSpan {
+	end := time.Now()
+	if !interval.IsOpen() {
+		end = interval.End.Timestamp
+	}
+	span := OtlpSpan{
+		TraceId:           traceId,
+		SpanId:            spanId,
+		ParentSpanId:      parentSpanId,
+		Name:              interval.Start.LogicalResourceId,
+		Kind:              OTLP_SPAN_KIND_INTERNAL,
+		StartTimeUnixNano: otlpTimestamp(interval.Start.Timestamp),
+		EndTimeUnixNano:   otlpTimestamp(end),
+		Attributes: []OtlpAttribute{
+			otlpAttribute("aws.cloudformation.stack_name", interval.Start.StackName),
+			otlpAttribute("aws.cloudformation.logical_resource_id", interval.Start.LogicalResourceId),
+			otlpAttribute("aws.cloudformation.physical_resource_id", interval.End.PhysicalResourceId),
+			otlpAttribute("aws.cloudformation.resource_type", interval.Start.ResourceType),
+			otlpAttribute("aws.cloudformation.resource_status", string(interval.End.ResourceStatus)),
+			otlpAttribute("aws.cloudformation.resource_status_reason", interval.End.ResourceStatusReason),
+			otlpAttribute("aws.cloudformation.outcome", string(interval.Outcome())),
+		},
+		Events: []OtlpSpanEvent{},
+	}
+	for _, event := range interval.Intermediate {
+		span.Events = append(span.Events, OtlpSpanEvent{
+			TimeUnixNano: otlpTimestamp(event.Timestamp),
+			Name:         string(event.ResourceStatus),
+			Attributes: []OtlpAttribute{
+				otlpAttribute("aws.cloudformation.event_id", event.EventId),
+				otlpAttribute("aws.cloudformation.resource_status_reason", event.ResourceStatusReason),
+			},
+		})
+	}
+	switch interval.Outcome() {
+	case aws.OUTCOME_FAILED:
+		span.Status = OtlpStatus{Code: OTLP_STATUS_CODE_ERROR, Message: interval.End.ResourceStatusReason}
+	case aws.OUTCOME_COMPLETE:
+		span.Status = OtlpStatus{Code: OTLP_STATUS_CODE_OK}
+	}
+	return span
+}
+
+func otlpAttribute(key, value string) OtlpAttribute {
+	return OtlpAttribute{Key: key, Value: OtlpAttributeValue{StringValue: value}}
+}
+
+func otlpTimestamp(timestamp time.Time) string {
+	return strconv.FormatInt(timestamp.UnixNano(), 10)
+}
+
+func otlpId(seed string, size int) string {
+	sum := sha256.Sum256([]byte(seed))
+	return hex.EncodeToString(sum[:size])
+}
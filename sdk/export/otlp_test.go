@@ -0,0 +1,75 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+func TestSendOtlp(t *testing.T) {
+	snapshot := newTestSnapshot(t)
+	received := OtlpDocument{}
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/traces" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("unexpected content type %q", got)
+		}
+		if got := r.Header.Get("X-Api-Key"); got != "secret" {
+			t.Errorf("unexpected api key header %q", got)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+	}))
+	defer collector.Close()
+	selection := aws.Selection{Stack: testRootArn, AllStacks: true, AllOperations: true}
+	if err := SendOtlp(context.Background(), collector.URL, map[string]string{"X-Api-Key": "secret"}, snapshot, selection); err != nil {
+		t.Fatalf("send failed: %v", err)
+	}
+	if len(received.ResourceSpans) != 1 || len(received.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected document shape: %+v", received)
+	}
+	spans := received.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 5 {
+		t.Fatalf("expected 5 spans, got %d", len(spans))
+	}
+	spanIds := map[string]OtlpSpan{}
+	for _, span := range spans {
+		if _, ok := spanIds[span.SpanId]; ok {
+			t.Fatalf("duplicate span id %s for %s", span.SpanId, span.Name)
+		}
+		spanIds[span.SpanId] = span
+	}
+	for _, span := range spans {
+		if span.TraceId != spans[0].TraceId {
+			t.Fatalf("expected a single trace, got %s and %s", spans[0].TraceId, span.TraceId)
+		}
+		if span.ParentSpanId == "" {
+			continue
+		}
+		if _, ok := spanIds[span.ParentSpanId]; !ok {
+			t.Fatalf("span %s has unknown parent %s", span.Name, span.ParentSpanId)
+		}
+		if span.Name == "Topic" && spanIds[span.ParentSpanId].Name != "test-child CREATE" {
+			t.Fatalf("expected Topic under nested operation, got %s", spanIds[span.ParentSpanId].Name)
+		}
+	}
+}
+
+func TestSendOtlpRejected(t *testing.T) {
+	snapshot := newTestSnapshot(t)
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad token", http.StatusUnauthorized)
+	}))
+	defer collector.Close()
+	selection := aws.Selection{Stack: testRootArn, Operation: snapshot.GetLatestOperation(testRootArn, false)}
+	if err := SendOtlp(context.Background(), collector.URL, nil, snapshot, selection); err == nil {
+		t.Fatalf("expected error for rejected request")
+	}
+}
@@ -0,0 +1,115 @@
+package export
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/null93/waterfall/sdk/aws"
+	"github.com/null93/waterfall/sdk/gui"
+)
+
+const (
+	SVG_LABEL_WIDTH  = 360
+	SVG_CHART_WIDTH  = 900
+	SVG_ROW_HEIGHT   = 18
+	SVG_HEADER       = 36
+	SVG_FOOTER       = 12
+	SVG_BAR_HEIGHT   = 12
+	SVG_MAX_TICKS    = 10
+	SVG_LABEL_LENGTH = 48
+)
+
+var svgTickSteps = []time.Duration{
+	time.Second, 2 * time.Second, 5 * time.Second, 10 * time.Second, 15 * time.Second, 30 * time.Second,
+	time.Minute, 2 * time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 2 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+func WriteSvg(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection) error {
+	intervals := getSelectedTreeIntervals(snapshot, selection)
+	width := SVG_LABEL_WIDTH + SVG_CHART_WIDTH + 20
+	height := SVG_HEADER + len(intervals)*SVG_ROW_HEIGHT + SVG_FOOTER
+	svg := strings.Builder{}
+	fmt.Fprintf(&svg, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="monospace" font-size="12">`+"\n", width, height, width, height)
+	svg.WriteString(`  <defs><pattern id="failed" width="6" height="6" patternUnits="userSpaceOnUse" patternTransform="rotate(45)"><rect width="3" height="6" fill="#000" fill-opacity="0.45"/></pattern></defs>` + "\n")
+	fmt.Fprintf(&svg, `  <rect width="%d" height="%d" fill="#ffffff"/>`+"\n", width, height)
+	if len(intervals) == 0 {
+		fmt.Fprintf(&svg, `  <text x="10" y="%d">No intervals found</text>`+"\n", SVG_HEADER)
+		svg.WriteString("</svg>\n")
+		_, err := io.WriteString(w, svg.String())
+		return err
+	}
+	windowInterval := aws.GetWindowInterval(&intervals)
+	windowStart := windowInterval.Start.Timestamp
+	windowDuration := windowInterval.End.Timestamp.Sub(windowStart)
+	if windowDuration <= 0 {
+		windowDuration = time.Second
+	}
+	scale := func(timestamp time.Time) float64 {
+		return SVG_LABEL_WIDTH + float64(timestamp.Sub(windowStart))/float64(windowDuration)*SVG_CHART_WIDTH
+	}
+	step := svgTickSteps[len(svgTickSteps)-1]
+	for _, candidate := range svgTickSteps {
+		if windowDuration/candidate <= SVG_MAX_TICKS {
+			step = candidate
+			break
+		}
+	}
+	fmt.Fprintf(&svg, `  <text x="10" y="20" font-weight="bold">LOGICAL RESOURCE ID</text>`+"\n")
+	for offset := time.Duration(0); offset <= windowDuration; offset += step {
+		x := scale(windowStart.Add(offset))
+		fmt.Fprintf(&svg, `  <line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#dddddd"/>`+"\n", x, SVG_HEADER-8, x, height-SVG_FOOTER)
+		fmt.Fprintf(&svg, `  <text x="%.1f" y="20" fill="#555555" text-anchor="middle">%s</text>`+"\n", x, offset)
+	}
+	for i, interval := range intervals {
+		y := SVG_HEADER + i*SVG_ROW_HEIGHT
+		label := interval.Start.LogicalResourceId
+		if interval.Start.IsOperation() {
+			label = "◯ " + label
+		}
+		label = strings.Repeat("  ", snapshot.GetStackDepth(interval.Start.StackId)) + label
+		if runes := []rune(label); len(runes) > SVG_LABEL_LENGTH {
+			label = string(runes[:SVG_LABEL_LENGTH-1]) + "…"
+		}
+		end := windowInterval.End.Timestamp
+		if !interval.IsOpen() {
+			end = interval.End.Timestamp
+		}
+		x := scale(interval.Start.Timestamp)
+		barWidth := scale(end) - x
+		if barWidth < 1 {
+			barWidth = 1
+		}
+		color := getHexColor(interval)
+		fmt.Fprintf(&svg, `  <g><title>%s</title>`+"\n", html.EscapeString(getSvgTitle(interval)))
+		fmt.Fprintf(&svg, `    <text x="10" y="%d" xml:space="preserve">%s</text>`+"\n", y+SVG_BAR_HEIGHT-1, html.EscapeString(label))
+		fmt.Fprintf(&svg, `    <line x1="%d" y1="%d" x2="%d" y2="%d" stroke="#eeeeee"/>`+"\n", SVG_LABEL_WIDTH, y+SVG_BAR_HEIGHT/2, SVG_LABEL_WIDTH+SVG_CHART_WIDTH, y+SVG_BAR_HEIGHT/2)
+		switch gui.GetIntervalRune(interval) {
+		case '□':
+			fmt.Fprintf(&svg, `    <rect x="%.1f" y="%d" width="%.1f" height="%d" fill="%s" fill-opacity="0.25" stroke="%s"/>`+"\n", x, y, barWidth, SVG_BAR_HEIGHT, color, color)
+		case '◩':
+			fmt.Fprintf(&svg, `    <rect x="%.1f" y="%d" width="%.1f" height="%d" fill="%s"/>`+"\n", x, y, barWidth, SVG_BAR_HEIGHT, color)
+			fmt.Fprintf(&svg, `    <rect x="%.1f" y="%d" width="%.1f" height="%d" fill="url(#failed)"/>`+"\n", x, y, barWidth, SVG_BAR_HEIGHT)
+		default:
+			fmt.Fprintf(&svg, `    <rect x="%.1f" y="%d" width="%.1f" height="%d" fill="%s"/>`+"\n", x, y, barWidth, SVG_BAR_HEIGHT, color)
+		}
+		svg.WriteString("  </g>\n")
+	}
+	svg.WriteString("</svg>\n")
+	_, err := io.WriteString(w, svg.String())
+	return err
+}
+
+func getSvgTitle(interval aws.Interval) string {
+	return fmt.Sprintf(
+		"%s (%s)\n%s → %s\n%s",
+		interval.Start.LogicalResourceId,
+		interval.Start.ResourceType,
+		interval.Start.ResourceStatus,
+		interval.End.ResourceStatus,
+		interval.Duration().Round(time.Second),
+	)
+}
@@ -0,0 +1,24 @@
+package export
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+func TestWriteSvgIncludesNestedStacks(t *testing.T) {
+	snapshot := newTestSnapshot(t)
+	selection := aws.Selection{Stack: testRootArn, Operation: snapshot.GetLatestOperation(testRootArn, false)}
+	output := bytes.Buffer{}
+	if err := WriteSvg(&output, snapshot, selection); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if rows := strings.Count(output.String(), "<g>"); rows != 5 {
+		t.Fatalf("expected 5 rows, got %d", rows)
+	}
+	if !strings.Contains(output.String(), ">  Topic</text>") {
+		t.Fatalf("expected indented nested stack row for Topic")
+	}
+}
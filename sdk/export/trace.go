@@ -0,0 +1,114 @@
+package export
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/null93/waterfall/sdk/aws"
+	"golang.org/x/exp/slices"
+)
+
+type TraceDocument struct {
+	TraceEvents     []TraceEvent `json:"traceEvents"`
+	DisplayTimeUnit string       `json:"displayTimeUnit"`
+}
+
+type TraceEvent struct {
+	Name      string         `json:"name"`
+	Category  string         `json:"cat,omitempty"`
+	Phase     string         `json:"ph"`
+	Timestamp int64          `json:"ts"`
+	Duration  int64          `json:"dur"`
+	ProcessId int            `json:"pid"`
+	ThreadId  int            `json:"tid"`
+	Scope     string         `json:"s,omitempty"`
+	Args      map[string]any `json:"args,omitempty"`
+}
+
+func NewTraceDocument(snapshot *aws.Snapshot, selection aws.Selection) TraceDocument {
+	document := TraceDocument{TraceEvents: []TraceEvent{}, DisplayTimeUnit: "ms"}
+	intervals := getSelectedTreeIntervals(snapshot, selection)
+	if len(intervals) == 0 {
+		return document
+	}
+	windowInterval := aws.GetWindowInterval(&intervals)
+	origin := windowInterval.Start.Timestamp
+	stackArns := snapshot.GetStackArns()
+	threadIds := map[string]map[string]int{}
+	for _, interval := range intervals {
+		stackArn := interval.Start.StackId
+		processId := slices.Index(stackArns, stackArn) + 1
+		if _, ok := threadIds[stackArn]; !ok {
+			threadIds[stackArn] = map[string]int{}
+			document.TraceEvents = append(
+				document.TraceEvents,
+				TraceEvent{Name: "process_name", Phase: "M", ProcessId: processId, Args: map[string]any{"name": aws.ExtractStackNameFromArn(stackArn)}},
+				TraceEvent{Name: "process_sort_index", Phase: "M", ProcessId: processId, Args: map[string]any{"sort_index": processId}},
+			)
+		}
+		logicalResourceId := interval.Start.LogicalResourceId
+		threadId, ok := threadIds[stackArn][logicalResourceId]
+		if !ok {
+			threadId = len(threadIds[stackArn]) + 1
+			threadIds[stackArn][logicalResourceId] = threadId
+			document.TraceEvents = append(
+				document.TraceEvents,
+				TraceEvent{Name: "thread_name", Phase: "M", ProcessId: processId, ThreadId: threadId, Args: map[string]any{"name": logicalResourceId}},
+			)
+		}
+		end := windowInterval.End.Timestamp
+		if !interval.IsOpen() {
+			end = interval.End.Timestamp
+		}
+		document.TraceEvents = append(document.TraceEvents, TraceEvent{
+			Name:      logicalResourceId,
+			Category:  interval.Start.ResourceType,
+			Phase:     "X",
+			Timestamp: traceTimestamp(origin, interval.Start.Timestamp),
+			Duration:  traceTimestamp(interval.Start.Timestamp, end),
+			ProcessId: processId,
+			ThreadId:  threadId,
+			Args: map[string]any{
+				"resource_type":          interval.Start.ResourceType,
+				"physical_resource_id":   interval.End.PhysicalResourceId,
+				"start_status":           interval.Start.ResourceStatus,
+				"end_status":             interval.End.ResourceStatus,
+				"resource_status_reason": interval.End.ResourceStatusReason,
+				"outcome":                interval.Outcome(),
+			},
+		})
+		for _, event := range interval.Intermediate {
+			document.TraceEvents = append(document.TraceEvents, TraceEvent{
+				Name:      string(event.ResourceStatus),
+				Category:  event.ResourceType,
+				Phase:     "i",
+				Timestamp: traceTimestamp(origin, event.Timestamp),
+				ProcessId: processId,
+				ThreadId:  threadId,
+				Scope:     "t",
+				Args: map[string]any{
+					"event_id":               event.EventId,
+					"resource_status_reason": event.ResourceStatusReason,
+				},
+			})
+		}
+	}
+	return document
+}
+
+func WriteTrace(w io.Writer, snapshot *aws.Snapshot, selection aws.Selection) error {
+	return json.NewEncoder(w).Encode(NewTraceDocument(snapshot, selection))
+}
+
+func getSelectedTreeIntervals(snapshot *aws.Snapshot, selection aws.Selection) []aws.Interval {
+	intervals := []aws.Interval{}
+	for _, operation := range snapshot.GetSelectedRootOperations(selection) {
+		intervals = append(intervals, snapshot.GetOperationTreeIntervals(operation)...)
+	}
+	return intervals
+}
+
+func traceTimestamp(from, to time.Time) int64 {
+	return to.Sub(from).Microseconds()
+}
@@ -14,13 +14,14 @@ type View string
 type State struct {
 	screen            tcell.Screen
 	dataSet           *aws.DataSet
+	snapshot          *aws.Snapshot
 	CurrentView       View
 	SelectedStack     string
 	SelectedOperation string
+	CompareOperation  string
 	selectedIndex     int
 	AllStacks         bool
 	AllOperations     bool
-	LastRefreshed     time.Time
 }
 
 const (
@@ -29,11 +30,16 @@ const (
 	VIEW_STACKS     View = "stacks"
 	VIEW_OPERATIONS View = "operations"
 	VIEW_DETAILS    View = "details"
+	VIEW_STATS      View = "stats"
+	VIEW_HISTORY    View = "history"
+	VIEW_COMPARE    View = "compare"
 )
 
 var (
 	DefaultStyle     = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorReset)
 	HighlightedStyle = tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack)
+	CriticalStyle    = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorFuchsia).Bold(true)
+	RootCauseStyle   = tcell.StyleDefault.Background(tcell.ColorDarkRed).Foreground(tcell.ColorWhite).Bold(true)
 )
 
 func NewState(screen tcell.Screen, dataSet *aws.DataSet) *State {
@@ -41,13 +47,23 @@ func NewState(screen tcell.Screen, dataSet *aws.DataSet) *State {
 	return &State{
 		screen:            screen,
 		dataSet:           dataSet,
+		snapshot:          dataSet.Snapshot(),
 		selectedIndex:     0,
 		CurrentView:       VIEW_WATERFALL,
 		SelectedStack:     dataSet.OriginalStackArn,
 		SelectedOperation: "",
+		CompareOperation:  "",
 		AllStacks:         false,
 		AllOperations:     false,
-		LastRefreshed:     time.Now(),
+	}
+}
+
+func (s *State) getSelection() aws.Selection {
+	return aws.Selection{
+		Stack:         s.SelectedStack,
+		Operation:     s.SelectedOperation,
+		AllStacks:     s.AllStacks,
+		AllOperations: s.AllOperations,
 	}
 }
 
@@ -56,7 +72,8 @@ func (s *State) ResetSelectedIndex() {
 }
 
 func (s *State) IncrementSelected() {
-	total := len(s.dataSet.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations))
+	snapshot := s.dataSet.Snapshot()
+	total := len(snapshot.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations))
 	if s.selectedIndex < total-1 {
 		s.selectedIndex = s.selectedIndex + 1
 	} else {
@@ -65,7 +82,8 @@ func (s *State) IncrementSelected() {
 }
 
 func (s *State) DecrementSelected() {
-	total := len(s.dataSet.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations))
+	snapshot := s.dataSet.Snapshot()
+	total := len(snapshot.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations))
 	if s.selectedIndex > 0 {
 		s.selectedIndex = s.selectedIndex - 1
 	} else {
@@ -73,8 +91,36 @@ func (s *State) DecrementSelected() {
 	}
 }
 
+func (s *State) JumpToRootCause() {
+	snapshot := s.dataSet.Snapshot()
+	rootCause := snapshot.GetSelectedRootCause(s.getSelection())
+	if rootCause == nil {
+		return
+	}
+	index := s.getIntervalIndex(snapshot, rootCause.Start.EventId)
+	if operation, ok := snapshot.GetIntervalOperation(*rootCause); index < 0 && ok {
+		s.SelectedStack = operation.StackId
+		s.SelectedOperation = operation.EventId
+		s.AllOperations = false
+		index = s.getIntervalIndex(snapshot, rootCause.Start.EventId)
+	}
+	if index >= 0 {
+		s.selectedIndex = index
+	}
+}
+
+func (s *State) getIntervalIndex(snapshot *aws.Snapshot, eventId string) int {
+	for i, interval := range snapshot.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations) {
+		if interval.Start.EventId == eventId {
+			return i
+		}
+	}
+	return -1
+}
+
 func (s *State) IncrementOperationSelected() {
-	events := s.dataSet.GetOperations(s.SelectedStack, s.AllStacks)
+	snapshot := s.dataSet.Snapshot()
+	events := snapshot.GetOperations(s.SelectedStack, s.AllStacks)
 	if len(events) > 1 {
 		for i, event := range events {
 			if event.EventId == s.SelectedOperation {
@@ -90,7 +136,8 @@ func (s *State) IncrementOperationSelected() {
 }
 
 func (s *State) DecrementOperationSelected() {
-	events := s.dataSet.GetOperations(s.SelectedStack, s.AllStacks)
+	snapshot := s.dataSet.Snapshot()
+	events := snapshot.GetOperations(s.SelectedStack, s.AllStacks)
 	if len(events) > 1 {
 		for i, event := range events {
 			if event.EventId == s.SelectedOperation {
@@ -106,7 +153,8 @@ func (s *State) DecrementOperationSelected() {
 }
 
 func (s *State) IncrementSelectedStack() {
-	stackArns := s.dataSet.GetStackArns()
+	snapshot := s.dataSet.Snapshot()
+	stackArns := snapshot.GetStackArns()
 	if len(stackArns) > 1 {
 		for i, stackArn := range stackArns {
 			if stackArn == s.SelectedStack {
@@ -122,7 +170,8 @@ func (s *State) IncrementSelectedStack() {
 }
 
 func (s *State) DecrementSelectedStack() {
-	stackArns := s.dataSet.GetStackArns()
+	snapshot := s.dataSet.Snapshot()
+	stackArns := snapshot.GetStackArns()
 	if len(stackArns) > 1 {
 		for i, stackArn := range stackArns {
 			if stackArn == s.SelectedStack {
@@ -138,12 +187,17 @@ func (s *State) DecrementSelectedStack() {
 }
 
 func (s *State) Render() {
+	s.snapshot = s.dataSet.Snapshot()
 	width, _ := s.screen.Size()
 	s.screen.Clear()
 	s.renderTopBar()
 	row := 14
 	switch s.CurrentView {
 	case VIEW_WATERFALL:
+		if rootCause := s.snapshot.GetSelectedRootCause(s.getSelection()); rootCause != nil {
+			s.renderRootCause(row, *rootCause)
+			row++
+		}
 		s.drawText(row, 3, width, DefaultStyle, "LOGICAL RESOURCE ID", nil)
 		s.drawText(row, 57, width, DefaultStyle, "INTERVAL", nil)
 		s.renderWaterfall(row + 1)
@@ -155,6 +209,12 @@ func (s *State) Render() {
 		s.renderOperation(row + 1)
 	case VIEW_DETAILS:
 		s.renderDetails(row + 1)
+	case VIEW_STATS:
+		s.renderStats(row + 1)
+	case VIEW_HISTORY:
+		s.renderHistory(row + 1)
+	case VIEW_COMPARE:
+		s.renderCompare(row + 1)
 	}
 	s.screen.Show()
 }
@@ -164,15 +224,21 @@ func (s *State) renderTopBar() {
 
 	switch s.CurrentView {
 	case VIEW_WATERFALL:
-		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Help: h, Stacks: s, Operations: o, Details: <Enter>", nil)
+		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Help: h, Stacks: s, Operations: o, Details: <Enter>, Stats: t, History: H, Compare: c", nil)
 	case VIEW_HELP:
-		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Stacks: s, Operations: o", nil)
+		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Stacks: s, Operations: o, Stats: t, History: H, Compare: c", nil)
 	case VIEW_STACKS:
-		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Help: h, Operations: o", nil)
+		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Help: h, Operations: o, Stats: t, History: H, Compare: c", nil)
 	case VIEW_OPERATIONS:
-		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Help: h, Stacks: s", nil)
+		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Help: h, Stacks: s, Stats: t, History: H, Compare: c, Pin Compare: p", nil)
 	case VIEW_DETAILS:
-		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Help: h, Stacks: s, Operations: o", nil)
+		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Help: h, Stacks: s, Operations: o, Stats: t, History: H, Compare: c", nil)
+	case VIEW_STATS:
+		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Help: h, Stacks: s, Operations: o, History: H, Compare: c", nil)
+	case VIEW_HISTORY:
+		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Help: h, Stacks: s, Operations: o, Stats: t, Compare: c", nil)
+	case VIEW_COMPARE:
+		s.drawText(0, 0, width, DefaultStyle, "Quit: <Esc>, Timeline: <Enter>, Help: h, Stacks: s, Operations: o, Stats: t, History: H", nil)
 	}
 
 	selectionHelp := "Selection: <Up> or <Down>"
@@ -192,17 +258,15 @@ func (s *State) renderTopBar() {
 	if s.AllOperations {
 		combineOperationsMessage = "Specific Operation"
 	}
-	s.drawText(2, 0, width, DefaultStyle, "Refresh Data: r, "+allStacksMessage+": S, "+combineOperationsMessage+": O", nil)
-
-	intervals := s.dataSet.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations)
+	s.drawText(2, 0, width, DefaultStyle, "Refresh Data: r, "+allStacksMessage+": S, "+combineOperationsMessage+": O, Root Cause: f", nil)
 
 	fillerRune := '━'
 	activeTabStyle := tcell.StyleDefault.Foreground(tcell.ColorWhite)
 	activeTabTextStyle := tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack)
 
-	s.drawText(3, 0, width, DefaultStyle, "┏━━━━━━━━━━━┓┏━━━━━━┓┏━━━━━━━━┓┏━━━━━━━━━━━━┓┏━━━━━━━━━┓", nil)
-	s.drawText(4, 0, width, DefaultStyle, "┃ WATERFALL ┃┃ HELP ┃┃ STACKS ┃┃ OPERATIONS ┃┃ DETAILS ┃", nil)
-	s.drawText(5, 0, width, DefaultStyle, "┻━━━━━━━━━━━┻┻━━━━━━┻┻━━━━━━━━┻┻━━━━━━━━━━━━┻┻━━━━━━━━━┻", &fillerRune)
+	s.drawText(3, 0, width, DefaultStyle, "┏━━━━━━━━━━━┓┏━━━━━━┓┏━━━━━━━━┓┏━━━━━━━━━━━━┓┏━━━━━━━━━┓┏━━━━━━━┓┏━━━━━━━━━┓┏━━━━━━━━━┓", nil)
+	s.drawText(4, 0, width, DefaultStyle, "┃ WATERFALL ┃┃ HELP ┃┃ STACKS ┃┃ OPERATIONS ┃┃ DETAILS ┃┃ STATS ┃┃ HISTORY ┃┃ COMPARE ┃", nil)
+	s.drawText(5, 0, width, DefaultStyle, "┻━━━━━━━━━━━┻┻━━━━━━┻┻━━━━━━━━┻┻━━━━━━━━━━━━┻┻━━━━━━━━━┻┻━━━━━━━┻┻━━━━━━━━━┻┻━━━━━━━━━┻", &fillerRune)
 
 	switch s.CurrentView {
 	case VIEW_WATERFALL:
@@ -230,33 +294,256 @@ func (s *State) renderTopBar() {
 		s.drawText(4, 45, width, activeTabStyle, "███████████", nil)
 		s.drawText(5, 45, width, activeTabStyle, "▀▀▀▀▀▀▀▀▀▀▀", nil)
 		s.drawText(4, 47, width, activeTabTextStyle, "DETAILS", nil)
-	}
+	case VIEW_STATS:
+		s.drawText(3, 56, width, activeTabStyle, "▄▄▄▄▄▄▄▄▄", nil)
+		s.drawText(4, 56, width, activeTabStyle, "█████████", nil)
+		s.drawText(5, 56, width, activeTabStyle, "▀▀▀▀▀▀▀▀▀", nil)
+		s.drawText(4, 58, width, activeTabTextStyle, "STATS", nil)
+	case VIEW_HISTORY:
+		s.drawText(3, 65, width, activeTabStyle, "▄▄▄▄▄▄▄▄▄▄▄", nil)
+		s.drawText(4, 65, width, activeTabStyle, "███████████", nil)
+		s.drawText(5, 65, width, activeTabStyle, "▀▀▀▀▀▀▀▀▀▀▀", nil)
+		s.drawText(4, 67, width, activeTabTextStyle, "HISTORY", nil)
+	case VIEW_COMPARE:
+		s.drawText(3, 76, width, activeTabStyle, "▄▄▄▄▄▄▄▄▄▄▄", nil)
+		s.drawText(4, 76, width, activeTabStyle, "███████████", nil)
+		s.drawText(5, 76, width, activeTabStyle, "▀▀▀▀▀▀▀▀▀▀▀", nil)
+		s.drawText(4, 78, width, activeTabTextStyle, "COMPARE", nil)
+	}
+
+	s.renderSummary(6)
+	s.drawText(13, 0, width, DefaultStyle, "", &fillerRune)
+}
 
-	s.drawText(6, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Last Refresh:", s.LastRefreshed.Format(time.TimeOnly)), nil)
+func (s *State) renderSummary(row int) {
+	width, _ := s.screen.Size()
+	intervals := s.snapshot.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations)
+	s.drawText(row+0, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Last Refresh:", s.snapshot.LastRefreshed.Format(time.TimeOnly)), nil)
 
 	if s.AllStacks {
-		s.drawText(7, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Stack:", "<ALL>"), nil)
+		s.drawText(row+1, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Stack:", "<ALL>"), nil)
 	} else {
-		s.drawText(7, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Stack:", aws.ExtractStackNameFromArn(s.SelectedStack)), nil)
+		s.drawText(row+1, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Stack:", aws.ExtractStackNameFromArn(s.SelectedStack)), nil)
 	}
 	if s.AllOperations {
-		s.drawText(8, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Operation:", "<ALL>"), nil)
+		s.drawText(row+2, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Operation:", "<ALL>"), nil)
 	} else {
-		s.drawText(8, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Operation:", s.SelectedOperation), nil)
+		s.drawText(row+2, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Operation:", s.SelectedOperation), nil)
 	}
-	s.drawText(9, 0, width, DefaultStyle, fmt.Sprintf("%-22s %d", "Stack Count:", len(s.dataSet.GetStackArns())), nil)
-	s.drawText(10, 0, width, DefaultStyle, fmt.Sprintf("%-22s %d", "Operation Count:", len(s.dataSet.GetOperations(s.SelectedStack, s.AllStacks))), nil)
-	s.drawText(11, 0, width, DefaultStyle, fmt.Sprintf("%-22s %d", "Interval Count:", len(intervals)), nil)
+	s.drawText(row+3, 0, width, DefaultStyle, fmt.Sprintf("%-22s %d", "Stack Count:", len(s.snapshot.GetStackArns())), nil)
+	s.drawText(row+4, 0, width, DefaultStyle, fmt.Sprintf("%-22s %d", "Operation Count:", len(s.snapshot.GetOperations(s.SelectedStack, s.AllStacks))), nil)
+	s.drawText(row+5, 0, width, DefaultStyle, fmt.Sprintf("%-22s %d", "Interval Count:", len(intervals)), nil)
 	if len(intervals) > 0 {
 		windowInterval := aws.GetWindowInterval(&intervals)
-		s.drawText(12, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Duration:", windowInterval.End.Timestamp.Sub(windowInterval.Start.Timestamp)), nil)
+		s.drawText(row+6, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Duration:", windowInterval.End.Timestamp.Sub(windowInterval.Start.Timestamp)), nil)
+	}
+}
+
+func (s *State) renderRootCause(row int, rootCause aws.Interval) {
+	width, _ := s.screen.Size()
+	fillerRune := ' '
+	message := fmt.Sprintf(
+		" Root Cause (jump: f): %s/%s %s: %s",
+		rootCause.Start.StackName,
+		rootCause.Start.LogicalResourceId,
+		rootCause.End.ResourceStatus,
+		rootCause.End.ResourceStatusReason,
+	)
+	s.drawText(row, 0, width, RootCauseStyle, message, &fillerRune)
+}
+
+func (s *State) renderStats(row int) {
+	width, height := s.screen.Size()
+	allStats := s.snapshot.GetSelectedStats(s.getSelection())
+	if len(allStats) == 0 {
+		s.drawText(row+1, 3, width, DefaultStyle, "No operation selected", nil)
+		return
+	}
+	for _, stats := range allStats {
+		actionCounts := []string{}
+		for _, action := range stats.GetActions() {
+			actionCounts = append(actionCounts, fmt.Sprintf("%s %d", action, stats.ActionCounts[action]))
+		}
+		outcomeCounts := []string{}
+		for _, outcome := range []aws.Outcome{aws.OUTCOME_COMPLETE, aws.OUTCOME_FAILED, aws.OUTCOME_IN_PROGRESS} {
+			outcomeCounts = append(outcomeCounts, fmt.Sprintf("%s %d", outcome, stats.OutcomeCounts[outcome]))
+		}
+		lines := []string{
+			fmt.Sprintf("%-22s %s %s %s", "Operation:", stats.Operation.StackName, stats.Operation.ResourceStatus, stats.Operation.Timestamp.Format(time.RFC3339)),
+			fmt.Sprintf("%-22s %d", "Interval Count:", stats.IntervalCount),
+			fmt.Sprintf("%-22s %s", "Duration:", stats.Duration.Round(time.Second)),
+			fmt.Sprintf("%-22s %s", "Total Duration:", stats.TotalDuration.Round(time.Second)),
+			fmt.Sprintf("%-22s %s", "Median Duration:", stats.MedianDuration.Round(time.Second)),
+			fmt.Sprintf("%-22s %s", "Actions:", strings.Join(actionCounts, ", ")),
+			fmt.Sprintf("%-22s %s", "Outcomes:", strings.Join(outcomeCounts, ", ")),
+			"",
+			fmt.Sprintf("%-44s  %5s  %10s  %10s  %10s  %10s  %10s", "RESOURCE TYPE", "COUNT", "TOTAL", "P50", "P90", "P99", "MAX"),
+		}
+		for _, typeStats := range stats.ResourceTypes {
+			lines = append(lines, fmt.Sprintf(
+				"%-44s  %5d  %10s  %10s  %10s  %10s  %10s",
+				typeStats.ResourceType,
+				typeStats.Count,
+				typeStats.Total.Round(time.Second),
+				typeStats.P50.Round(time.Second),
+				typeStats.P90.Round(time.Second),
+				typeStats.P99.Round(time.Second),
+				typeStats.Max.Round(time.Second),
+			))
+		}
+		lines = append(lines, "")
+		for _, line := range lines {
+			if row >= height {
+				return
+			}
+			s.drawText(row, 0, width, DefaultStyle, line, nil)
+			row++
+		}
+	}
+}
+
+func (s *State) renderHistory(row int) {
+	width, height := s.screen.Size()
+	intervals := s.snapshot.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations)
+
+	if len(intervals) == 0 {
+		return
+	}
+
+	selected := intervals[s.selectedIndex]
+	history := s.snapshot.GetResourceHistory(selected.Start.StackId, selected.Start.LogicalResourceId)
+	outlierStyle := tcell.StyleDefault.Foreground(tcell.ColorRed).Bold(true)
+
+	s.drawText(row, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Stack:", aws.ExtractStackNameFromArn(history.StackArn)), nil)
+	s.drawText(row+1, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "LogicalResourceId:", history.LogicalResourceId), nil)
+	s.drawText(row+2, 0, width, DefaultStyle, fmt.Sprintf("%-22s %d", "Interval Count:", len(history.Entries)), nil)
+	s.drawText(row+3, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Median Duration:", history.MedianDuration.Round(time.Second)), nil)
+	s.drawText(row+4, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s", "Trend:", GetSparkline(history.GetDurations())), nil)
+	s.drawText(row+6, 0, width, DefaultStyle, fmt.Sprintf("   %-36s  %-20s  %-36s  %10s  %10s", "OPERATION", "TIMESTAMP", "RESOURCE STATUS", "DURATION", "VS MEDIAN"), nil)
+	row += 7
+
+	for _, entry := range history.Entries {
+		if row >= height {
+			return
+		}
+		textStyle := DefaultStyle
+		var fillerRunePtr *rune = nil
+		if entry.IsOutlier {
+			textStyle = outlierStyle
+		}
+		if entry.Interval.Start.EventId == selected.Start.EventId {
+			textStyle = HighlightedStyle
+			fillerRune := ' '
+			fillerRunePtr = &fillerRune
+		}
+		outlierIndicator := "   "
+		if entry.IsOutlier {
+			outlierIndicator = " ! "
+		}
+		ratio := "-"
+		if history.MedianDuration > 0 {
+			ratio = fmt.Sprintf("%.1fx", float64(entry.Duration)/float64(history.MedianDuration))
+		}
+		s.drawText(
+			row,
+			0,
+			width,
+			textStyle,
+			fmt.Sprintf(
+				"%s%-36s  %-20s  %-36s  %10s  %10s",
+				outlierIndicator,
+				entry.Operation.EventId,
+				entry.Operation.Timestamp.Format(time.RFC3339),
+				entry.Interval.End.ResourceStatus,
+				entry.Duration.Round(time.Second),
+				ratio,
+			),
+			fillerRunePtr,
+		)
+		row++
+	}
+}
+
+func (s *State) renderCompare(row int) {
+	width, height := s.screen.Size()
+	to, ok := s.snapshot.GetOperation(s.SelectedOperation)
+	if !ok {
+		s.drawText(row+1, 3, width, DefaultStyle, "No operation selected", nil)
+		return
+	}
+	fromLabel := "From (previous):"
+	from, ok := s.snapshot.GetOperation(s.CompareOperation)
+	if ok && from.EventId != to.EventId {
+		if from.StackId != to.StackId {
+			s.drawText(row+1, 3, width, DefaultStyle, "Pinned operation belongs to a different stack, unpin with p", nil)
+			return
+		}
+		fromLabel = "From (pinned):"
+	} else if from, ok = s.snapshot.GetPreviousOperation(to); !ok {
+		s.drawText(row+1, 3, width, DefaultStyle, "No previous operation to compare with", nil)
+		return
+	}
+	fromOnlyStyle := tcell.StyleDefault.Foreground(tcell.ColorRed)
+	toOnlyStyle := tcell.StyleDefault.Foreground(tcell.ColorGreen)
+	slowerStyle := tcell.StyleDefault.Foreground(tcell.ColorYellow)
+
+	s.drawText(row, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s %s %s", fromLabel, from.EventId, from.ResourceStatus, from.Timestamp.Format(time.RFC3339)), nil)
+	s.drawText(row+1, 0, width, DefaultStyle, fmt.Sprintf("%-22s %s %s %s", "To:", to.EventId, to.ResourceStatus, to.Timestamp.Format(time.RFC3339)), nil)
+	s.drawText(row+3, 0, width, DefaultStyle, fmt.Sprintf("   %-44s  %10s  %10s  %10s  %10s  %10s  %10s", "LOGICAL RESOURCE ID", "FROM START", "TO START", "START Δ", "FROM DUR", "TO DUR", "DURATION Δ"), nil)
+	row += 4
+
+	for _, comparison := range s.snapshot.CompareOperations(from, to) {
+		if row >= height {
+			return
+		}
+		textStyle := DefaultStyle
+		marker := "   "
+		fromStart, toStart, fromDuration, toDuration := "-", "-", "-", "-"
+		startDelta, durationDelta := "-", "-"
+		stackArn := ""
+		if comparison.From != nil {
+			stackArn = comparison.From.Start.StackId
+			fromStart = comparison.FromOffset.Round(time.Second).String()
+			fromDuration = comparison.From.Duration().Round(time.Second).String()
+		}
+		if comparison.To != nil {
+			stackArn = comparison.To.Start.StackId
+			toStart = comparison.ToOffset.Round(time.Second).String()
+			toDuration = comparison.To.Duration().Round(time.Second).String()
+		}
+		switch {
+		case comparison.IsFromOnly():
+			textStyle = fromOnlyStyle
+			marker = " - "
+		case comparison.IsToOnly():
+			textStyle = toOnlyStyle
+			marker = " + "
+		default:
+			startDelta = FormatDelta(comparison.OffsetDelta())
+			durationDelta = FormatDelta(comparison.DurationDelta())
+			if comparison.DurationDelta().Round(time.Second) > 0 {
+				textStyle = slowerStyle
+			}
+		}
+		logicalResourceId := strings.Repeat("  ", s.snapshot.GetStackDepth(stackArn)) + comparison.LogicalResourceId
+		if runes := []rune(logicalResourceId); len(runes) > 44 {
+			logicalResourceId = string(runes[:43]) + "…"
+		}
+		s.drawText(
+			row,
+			0,
+			width,
+			textStyle,
+			fmt.Sprintf("%s%-44s  %10s  %10s  %10s  %10s  %10s  %10s", marker, logicalResourceId, fromStart, toStart, startDelta, fromDuration, toDuration, durationDelta),
+			nil,
+		)
+		row++
 	}
-	s.drawText(13, 0, width, DefaultStyle, "", &fillerRune)
 }
 
 func (s *State) renderDetails(row int) {
 	width, _ := s.screen.Size()
-	intervals := s.dataSet.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations)
+	intervals := s.snapshot.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations)
 
 	if len(intervals) == 0 {
 		return
@@ -311,31 +598,46 @@ func (s *State) renderStacks(row int) {
 		"STACK ARN",
 		nil,
 	)
-	for i, stackArn := range s.dataSet.GetStackArns() {
+	for i, stackArn := range s.snapshot.GetStackArns() {
 		textStyle := DefaultStyle
 		if stackArn == s.SelectedStack && !s.AllStacks {
 			textStyle = HighlightedStyle
 		}
+		stackText := s.getStackIndent(stackArn) + aws.ExtractStackNameFromArn(stackArn)
+		if stackErr := s.snapshot.GetStackError(stackArn); stackErr != nil {
+			stackText += fmt.Sprintf(" (refresh failed: %s)", stackErr)
+		}
+		if templateErr := s.snapshot.GetStackTemplateError(stackArn); templateErr != nil {
+			stackText += fmt.Sprintf(" (template failed: %s)", templateErr)
+		}
 		s.drawText(
 			row+i+1,
 			0,
 			width,
 			textStyle,
-			aws.ExtractStackNameFromArn(stackArn),
+			stackText,
 			nil,
 		)
 	}
 }
 
+func (s *State) getStackIndent(stackArn string) string {
+	depth := s.snapshot.GetStackDepth(stackArn)
+	if depth == 0 {
+		return ""
+	}
+	return strings.Repeat("   ", depth-1) + "└─ "
+}
+
 func (s *State) renderOperation(row int) {
 	width, _ := s.screen.Size()
-	events := s.dataSet.GetOperations(s.SelectedStack, s.AllStacks)
+	events := s.snapshot.GetOperations(s.SelectedStack, s.AllStacks)
 	s.drawText(
 		row,
 		0,
 		width,
 		DefaultStyle,
-		fmt.Sprintf("%-36s  %-20s  %-18s  %s", "EVENT ID", "TIMESTAMP", "RESOURCE STATUS", "LOGICAL RESOURCE ID"),
+		fmt.Sprintf("   %-36s  %-20s  %-18s  %s", "EVENT ID", "TIMESTAMP", "RESOURCE STATUS", "LOGICAL RESOURCE ID"),
 		nil,
 	)
 	for i, _ := range events {
@@ -344,12 +646,16 @@ func (s *State) renderOperation(row int) {
 		if event.EventId == s.SelectedOperation && !s.AllOperations {
 			textStyle = HighlightedStyle
 		}
+		marker := "   "
+		if event.EventId == s.CompareOperation {
+			marker = " * "
+		}
 		s.drawText(
 			row+i+1,
 			0,
 			width,
 			textStyle,
-			fmt.Sprintf("%-36s  %-20s  %-18s  %s", event.EventId, event.Timestamp.Format(time.RFC3339), event.ResourceStatus, event.LogicalResourceId),
+			fmt.Sprintf("%s%-36s  %-20s  %-18s  %s", marker, event.EventId, event.Timestamp.Format(time.RFC3339), event.ResourceStatus, event.LogicalResourceId),
 			nil,
 		)
 	}
@@ -358,12 +664,16 @@ func (s *State) renderOperation(row int) {
 func (s *State) renderWaterfall(row int) {
 	textWidth := 52
 	width, height := s.screen.Size()
-	intervals := s.dataSet.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations)
+	intervals := s.snapshot.GetSortedIntervals(s.SelectedStack, s.SelectedOperation, s.AllStacks, s.AllOperations)
 	if len(intervals) == 0 {
 		s.drawText(row+1, 3, textWidth, DefaultStyle, "No intervals found", nil)
 		return
 	}
 	windowInterval := aws.GetWindowInterval(&intervals)
+	criticalPath := map[string]bool{}
+	for _, interval := range s.snapshot.GetSelectedCriticalPath(s.getSelection()) {
+		criticalPath[interval.Start.EventId] = true
+	}
 	totalIntervals := len(intervals)
 	totalRows := height - row
 	startIndex := 0
@@ -381,10 +691,16 @@ func (s *State) renderWaterfall(row int) {
 			continue
 		}
 		textStyle := DefaultStyle
+		if criticalPath[interval.Start.EventId] {
+			textStyle = CriticalStyle
+		}
 		backgroundColor := tcell.ColorReset
 		logicalResourceId := "-"
 		if interval.Start != nil {
 			logicalResourceId = interval.Start.LogicalResourceId
+			if s.AllStacks {
+				logicalResourceId = strings.Repeat("  ", s.snapshot.GetStackDepth(interval.Start.StackId)) + logicalResourceId
+			}
 		}
 		var fillerRunePtr *rune = nil
 		if i == s.selectedIndex {
@@ -460,9 +776,15 @@ func (s *State) renderLegend(row int) {
 	s.drawText(row+21, 8, 64, DefaultStyle, "IMPORT_ROLLBACK_COMPLETE", nil)
 	s.drawText(row+22, 0, 18, darkOrange, "◩◩◩◩◩◩", nil)
 	s.drawText(row+22, 8, 64, DefaultStyle, "IMPORT_ROLLBACK_FAILED", nil)
+	s.drawText(row+23, 0, 18, CriticalStyle, "Resource", nil)
+	criticalPathText := "CRITICAL PATH (from current templates)"
+	if s.snapshot.IsSelectedCriticalPathFallback(s.getSelection()) {
+		criticalPathText = "CRITICAL PATH (timing fallback, no templates)"
+	}
+	s.drawText(row+23, 10, 64, DefaultStyle, criticalPathText, nil)
 }
 
-func getIntervalRune(interval aws.Interval) rune {
+func GetIntervalRune(interval aws.Interval) rune {
 	if interval.End == nil {
 		return '■'
 	}
@@ -476,7 +798,7 @@ func getIntervalRune(interval aws.Interval) rune {
 	return '■'
 }
 
-func getIntervalColor(interval aws.Interval) tcell.Color {
+func GetIntervalColor(interval aws.Interval) tcell.Color {
 	status := string(interval.End.ResourceStatus)
 	switch true {
 	case strings.HasPrefix(status, "UPDATE_ROLLBACK_"):
@@ -499,6 +821,33 @@ func getIntervalColor(interval aws.Interval) tcell.Color {
 	return tcell.ColorReset
 }
 
+func FormatDelta(delta time.Duration) string {
+	delta = delta.Round(time.Second)
+	if delta > 0 {
+		return "+" + delta.String()
+	}
+	return delta.String()
+}
+
+func GetSparkline(durations []time.Duration) string {
+	levels := []rune("▁▂▃▄▅▆▇█")
+	longest := time.Duration(0)
+	for _, duration := range durations {
+		if duration > longest {
+			longest = duration
+		}
+	}
+	sparkline := []rune{}
+	for _, duration := range durations {
+		level := 0
+		if longest > 0 {
+			level = int(float64(duration) / float64(longest) * float64(len(levels)-1))
+		}
+		sparkline = append(sparkline, levels[level])
+	}
+	return string(sparkline)
+}
+
 func drawInterval(s tcell.Screen, row, colStart, colEnd int, windowInterval, interval aws.Interval, backgroundColor tcell.Color) {
 	windowEnd := windowInterval.End.Timestamp
 	windowStart := windowInterval.Start.Timestamp
@@ -510,8 +859,8 @@ func drawInterval(s tcell.Screen, row, colStart, colEnd int, windowInterval, int
 	colWidth := colEnd - colStart
 	secondsInCol := windowEnd.Sub(windowStart).Seconds() / float64(colWidth)
 	carryOver := windowStart.Add(0)
-	intervalRune := getIntervalRune(interval)
-	intervalColor := getIntervalColor(interval)
+	intervalRune := GetIntervalRune(interval)
+	intervalColor := GetIntervalColor(interval)
 	intervalStyle := tcell.StyleDefault.Background(backgroundColor).Foreground(intervalColor).Bold(true)
 	lineStyle := tcell.StyleDefault.Background(backgroundColor).Foreground(tcell.ColorGray).Dim(true)
 	for i := 0; i < colWidth; i++ {
@@ -527,13 +876,16 @@ func drawInterval(s tcell.Screen, row, colStart, colEnd int, windowInterval, int
 
 func (s *State) drawText(row, colStart, colEnd int, style tcell.Style, text string, filler *rune) {
 	maxLength := colEnd - colStart
-	if len(text) > maxLength {
-		text = text[:maxLength-1]
-		text = text + "…"
+	if maxLength <= 0 {
+		return
+	}
+	runes := []rune(text)
+	if len(runes) > maxLength {
+		runes = append(runes[:maxLength-1], '…')
 	}
 
 	col := colStart
-	for _, r := range []rune(text) {
+	for _, r := range runes {
 		if col >= colEnd {
 			return
 		}
@@ -0,0 +1,110 @@
+package gui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/null93/waterfall/sdk/aws"
+)
+
+const (
+	PRINT_MIN_WIDTH     = 80
+	PRINT_LEGEND_HEIGHT = 24
+)
+
+func Print(w io.Writer, dataSet *aws.DataSet, selection aws.Selection, width int, color bool) error {
+	if width < PRINT_MIN_WIDTH {
+		return fmt.Errorf("width must be at least %d", PRINT_MIN_WIDTH)
+	}
+	snapshot := dataSet.Snapshot()
+	intervalCount := len(snapshot.GetSelectedIntervals(selection))
+	if intervalCount == 0 {
+		intervalCount = 2
+	}
+	height := 9 + intervalCount + 1 + PRINT_LEGEND_HEIGHT
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		return err
+	}
+	defer screen.Fini()
+	screen.SetSize(width, height)
+	s := NewState(screen, dataSet)
+	s.SelectedStack = selection.Stack
+	s.SelectedOperation = selection.Operation
+	s.AllStacks = selection.AllStacks
+	s.AllOperations = selection.AllOperations
+	s.selectedIndex = -1
+	s.renderStatic()
+	return writeScreen(w, screen, color)
+}
+
+func (s *State) renderStatic() {
+	width, height := s.screen.Size()
+	fillerRune := '━'
+	s.screen.Clear()
+	s.renderSummary(0)
+	s.drawText(7, 0, width, DefaultStyle, "", &fillerRune)
+	s.drawText(8, 3, width, DefaultStyle, "LOGICAL RESOURCE ID", nil)
+	s.drawText(8, 57, width, DefaultStyle, "INTERVAL", nil)
+	s.renderWaterfall(9)
+	s.renderLegend(height - PRINT_LEGEND_HEIGHT)
+	s.screen.Show()
+}
+
+func writeScreen(w io.Writer, screen tcell.Screen, color bool) error {
+	width, height := screen.Size()
+	for row := 0; row < height; row++ {
+		line := strings.Builder{}
+		resetSequence := getAnsiSequence(DefaultStyle)
+		lastSequence := resetSequence
+		for col := 0; col < width; col++ {
+			r, _, style, _ := screen.GetContent(col, row)
+			if sequence := getAnsiSequence(style); color && sequence != lastSequence {
+				line.WriteString(sequence)
+				lastSequence = sequence
+			}
+			line.WriteRune(r)
+		}
+		text := strings.TrimRight(line.String(), " ")
+		if lastSequence != resetSequence {
+			text += resetSequence
+		}
+		if _, err := io.WriteString(w, text+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getAnsiSequence(style tcell.Style) string {
+	fg, bg, attrs := style.Decompose()
+	codes := []string{"0"}
+	if attrs&tcell.AttrBold != 0 {
+		codes = append(codes, "1")
+	}
+	if attrs&tcell.AttrDim != 0 {
+		codes = append(codes, "2")
+	}
+	if code := getAnsiColor(fg, "38"); code != "" {
+		codes = append(codes, code)
+	}
+	if code := getAnsiColor(bg, "48"); code != "" {
+		codes = append(codes, code)
+	}
+	return "\x1b[" + strings.Join(codes, ";") + "m"
+}
+
+func getAnsiColor(color tcell.Color, prefix string) string {
+	switch {
+	case color == tcell.ColorDefault || color == tcell.ColorReset:
+		return ""
+	case color.IsRGB():
+		r, g, b := color.RGB()
+		return fmt.Sprintf("%s;2;%d;%d;%d", prefix, r, g, b)
+	case color.Valid():
+		return fmt.Sprintf("%s;5;%d", prefix, color-tcell.ColorValid)
+	}
+	return ""
+}